@@ -1,7 +1,6 @@
 package controller
 
 import (
-	"context"
 	"encoding/json"
 	"log"
 	"math"
@@ -9,8 +8,9 @@ import (
 	"strconv"
 
 	"absensi/database"
+	"absensi/events"
+	"absensi/jobs"
 	"absensi/models"
-	"absensi/utils"
 
 	"github.com/jackc/pgx/v5"
 )
@@ -23,6 +23,18 @@ func CheckIn(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// User yang dinonaktifkan atau dihapus tidak boleh mencatat kehadiran
+	active, err := isActiveUser(r.Context(), userID)
+	if err != nil {
+		log.Println("Error checking user status:", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if !active {
+		http.Error(w, "Account is deactivated", http.StatusForbidden)
+		return
+	}
+
 	// Parsing request body untuk mendapatkan latitude & longitude
 	var requestData struct {
 		Latitude  float64 `json:"latitude"`
@@ -34,9 +46,19 @@ func CheckIn(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Log kehadiran dan email notifikasi disimpan dalam satu transaksi,
+	// email dikirim worker outbox sehingga kegagalan email tidak menggagalkan check-in
+	tx, err := database.DB.Begin(r.Context())
+	if err != nil {
+		log.Println("Error starting check-in transaction:", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback(r.Context())
+
 	// Cek apakah ada attendance record untuk user
 	var attendanceID string
-	err := database.DB.QueryRow(
+	err = tx.QueryRow(
 		r.Context(),
 		`SELECT id FROM attendance WHERE user_id = $1 ORDER BY created_at DESC LIMIT 1`,
 		userID,
@@ -45,7 +67,7 @@ func CheckIn(w http.ResponseWriter, r *http.Request) {
 	// Jika tidak ada, buat attendance baru
 	if err == pgx.ErrNoRows {
 		query := `INSERT INTO attendance (user_id, created_at) VALUES ($1, NOW()) RETURNING id`
-		err = database.DB.QueryRow(r.Context(), query, userID).Scan(&attendanceID)
+		err = tx.QueryRow(r.Context(), query, userID).Scan(&attendanceID)
 		if err != nil {
 			log.Println("Error creating new attendance record:", err)
 			http.Error(w, "Failed to create attendance record", http.StatusInternalServerError)
@@ -58,33 +80,43 @@ func CheckIn(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Simpan data check-in di attendance_logs
-	query := `INSERT INTO attendance_logs (attendance_id, latitude, longitude, created_at) VALUES ($1, $2, $3, NOW()) RETURNING id`
-	_, err = database.DB.Exec(r.Context(), query, attendanceID, requestData.Latitude, requestData.Longitude)
+	var entry models.AttendanceLog
+	query := `
+        INSERT INTO attendance_logs (attendance_id, type, latitude, longitude, created_at) VALUES ($1, $2, $3, $4, NOW())
+        RETURNING id::TEXT, attendance_id::TEXT, type, latitude, longitude, created_at`
+	err = tx.QueryRow(r.Context(), query, attendanceID, models.LogTypeCheckIn, requestData.Latitude, requestData.Longitude).
+		Scan(&entry.ID, &entry.AttendanceID, &entry.Type, &entry.Latitude, &entry.Longitude, &entry.CreatedAt)
 	if err != nil {
 		log.Println("Error inserting check-in:", err)
 		http.Error(w, "Failed to check-in", http.StatusInternalServerError)
 		return
 	}
 
-	// Ambil email user untuk notifikasi
-	var email string
-	err = database.DB.QueryRow(r.Context(), "SELECT email FROM users WHERE id = $1", userID).Scan(&email)
-	if err != nil {
-		log.Println("Error fetching user email:", err)
-		http.Error(w, "Failed to retrieve user email", http.StatusInternalServerError)
+	// Event webhook ikut transaksi sehingga hanya terkirim jika check-in tersimpan
+	if err := jobs.EnqueueWebhookEvent(r.Context(), tx, models.WebhookAttendanceCheckedIn, attendanceWebhookData(userID, entry)); err != nil {
+		log.Println("Error queueing check-in webhook:", err)
+		http.Error(w, "Failed to check-in", http.StatusInternalServerError)
 		return
 	}
 
-	// Kirim notifikasi email
-	err = utils.SendEmailNotification(email, "Check-in Berhasil", "Anda berhasil check-in hari ini!")
-	if err != nil {
-		log.Println("Error sending email:", err)
-		http.Error(w, "Failed to send email notification", http.StatusInternalServerError)
+	// Antrikan tanda terima sesuai preferensi notifikasi dan bahasa user
+	if err := queueAttendanceNotification(r.Context(), tx, userID, models.LogTypeCheckIn); err != nil {
+		log.Println("Error queueing check-in notification:", err)
+		http.Error(w, "Failed to check-in", http.StatusInternalServerError)
 		return
 	}
 
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Println("Error committing check-in:", err)
+		http.Error(w, "Failed to check-in", http.StatusInternalServerError)
+		return
+	}
+
+	// Kirim event ke live board
+	publishAttendanceEvent(r.Context(), events.TypeCheckIn, userID, requestData.Latitude, requestData.Longitude)
+
 	// Respon sukses
-	json.NewEncoder(w).Encode(map[string]string{"message": "Check-in berhasil dan email telah dikirim!"})
+	json.NewEncoder(w).Encode(map[string]string{"message": "Check-in berhasil!"})
 }
 
 
@@ -96,6 +128,18 @@ func CheckOut(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// User yang dinonaktifkan atau dihapus tidak boleh mencatat kehadiran
+	active, err := isActiveUser(r.Context(), userID)
+	if err != nil {
+		log.Println("Error checking user status:", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if !active {
+		http.Error(w, "Account is deactivated", http.StatusForbidden)
+		return
+	}
+
 	// Parsing request body untuk mendapatkan latitude & longitude
 	var requestData struct {
 		Latitude  float64 `json:"latitude"`
@@ -107,9 +151,18 @@ func CheckOut(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Log kehadiran dan email notifikasi disimpan dalam satu transaksi
+	tx, err := database.DB.Begin(r.Context())
+	if err != nil {
+		log.Println("Error starting check-out transaction:", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback(r.Context())
+
 	// Ambil attendance_id berdasarkan user_id
 	var attendanceID string
-	err := database.DB.QueryRow(r.Context(), `SELECT id FROM attendance WHERE user_id = $1 ORDER BY created_at DESC LIMIT 1`, userID).Scan(&attendanceID)
+	err = tx.QueryRow(r.Context(), `SELECT id FROM attendance WHERE user_id = $1 ORDER BY created_at DESC LIMIT 1`, userID).Scan(&attendanceID)
 	if err != nil {
 		log.Println("Error fetching attendance ID:", err)
 		http.Error(w, "Attendance record not found", http.StatusNotFound)
@@ -117,33 +170,43 @@ func CheckOut(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Simpan data check-out di database
-	query := `INSERT INTO attendance_logs (attendance_id, latitude, longitude, created_at) VALUES ($1, $2, $3, NOW()) RETURNING id`
-	_, err = database.DB.Exec(r.Context(), query, attendanceID, requestData.Latitude, requestData.Longitude)
+	var entry models.AttendanceLog
+	query := `
+        INSERT INTO attendance_logs (attendance_id, type, latitude, longitude, created_at) VALUES ($1, $2, $3, $4, NOW())
+        RETURNING id::TEXT, attendance_id::TEXT, type, latitude, longitude, created_at`
+	err = tx.QueryRow(r.Context(), query, attendanceID, models.LogTypeCheckOut, requestData.Latitude, requestData.Longitude).
+		Scan(&entry.ID, &entry.AttendanceID, &entry.Type, &entry.Latitude, &entry.Longitude, &entry.CreatedAt)
 	if err != nil {
 		log.Println("Error inserting check-out:", err)
 		http.Error(w, "Failed to check-out", http.StatusInternalServerError)
 		return
 	}
 
-	// Ambil email user dari database
-	var email string
-	err = database.DB.QueryRow(r.Context(), "SELECT email FROM users WHERE id = $1", userID).Scan(&email)
-	if err != nil {
-		log.Println("Error fetching user email:", err)
-		http.Error(w, "Failed to retrieve user email", http.StatusInternalServerError)
+	// Event webhook ikut transaksi sehingga hanya terkirim jika check-out tersimpan
+	if err := jobs.EnqueueWebhookEvent(r.Context(), tx, models.WebhookAttendanceCheckedOut, attendanceWebhookData(userID, entry)); err != nil {
+		log.Println("Error queueing check-out webhook:", err)
+		http.Error(w, "Failed to check-out", http.StatusInternalServerError)
 		return
 	}
 
-	// Kirim notifikasi email
-	err = utils.SendEmailNotification(email, "Check-out Berhasil", "Anda berhasil check-out hari ini!")
-	if err != nil {
-		log.Println("Error sending email:", err)
-		http.Error(w, "Failed to send email notification", http.StatusInternalServerError)
+	// Antrikan tanda terima sesuai preferensi notifikasi dan bahasa user
+	if err := queueAttendanceNotification(r.Context(), tx, userID, models.LogTypeCheckOut); err != nil {
+		log.Println("Error queueing check-out notification:", err)
+		http.Error(w, "Failed to check-out", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Println("Error committing check-out:", err)
+		http.Error(w, "Failed to check-out", http.StatusInternalServerError)
 		return
 	}
 
+	// Kirim event ke live board
+	publishAttendanceEvent(r.Context(), events.TypeCheckOut, userID, requestData.Latitude, requestData.Longitude)
+
 	// Beri response sukses
-	json.NewEncoder(w).Encode(map[string]string{"message": "Check-out berhasil dan email telah dikirim!"})
+	json.NewEncoder(w).Encode(map[string]string{"message": "Check-out berhasil!"})
 }
 
 // HaversineDistance menghitung jarak antara dua titik koordinat dalam meter
@@ -166,6 +229,7 @@ func HaversineDistance(lat1, lon1, lat2, lon2 float64) float64 {
 
 
 
+// GetMonthlyAttendance mengembalikan rekap harian satu bulan milik user yang login dalam format JSON, CSV atau XLSX
 func GetMonthlyAttendance(w http.ResponseWriter, r *http.Request) {
     // Ambil user_id dari context (pastikan middleware sudah berjalan)
     userID := r.Context().Value("user_id")
@@ -201,36 +265,27 @@ func GetMonthlyAttendance(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    // Ambil data dari database
-    query := `
-        SELECT id, user_id, check_in, check_out, latitude, longitude, status
-    	FROM attendance
-    	WHERE user_id = $1 AND EXTRACT(MONTH FROM check_in) = $2 AND EXTRACT(YEAR FROM check_in) = $3
-    	ORDER BY check_in ASC`
+    // Export CSV/XLSX jika diminta lewat ?format= atau header Accept
+    if format := exportFormat(r); format != formatJSON {
+        writeAttendanceExport(w, r, format, month, year, false, []string{userIDStr})
+        return
+    }
 
-    rows, err := database.DB.Query(context.Background(), query, userIDStr, month, year)
+    // Rekap harian yang sama dengan export: satu baris per tanggal dari attendance_logs
+    recaps, err := buildMonthlyRecaps(r.Context(), month, year, false, []string{userIDStr}, "")
     if err != nil {
+        log.Println("Error building monthly attendance:", err)
         http.Error(w, "Failed to fetch data", http.StatusInternalServerError)
         return
     }
-    defer rows.Close()
-
-    var attendances []models.Attendance
-    for rows.Next() {
-        var att models.Attendance
-        err := rows.Scan(&att.ID, &att.UserID, &att.CheckIn, &att.CheckOut, &att.Latitude, &att.Longitude, &att.Status)
-        if err != nil {
-            http.Error(w, "Error scanning data", http.StatusInternalServerError)
-            return
-        }
-        attendances = append(attendances, att)
-    }
 
     // Return response
     w.Header().Set("Content-Type", "application/json")
-    json.NewEncoder(w).Encode(attendances)
+    json.NewEncoder(w).Encode(recapDays(recaps))
 }
 
+// GetAllUsersMonthlyAttendance mengembalikan rekap harian satu bulan untuk semua user dalam scope
+// dalam format JSON, CSV atau XLSX
 func GetAllUsersMonthlyAttendance(w http.ResponseWriter, r *http.Request) {
 	// Ambil paramneter bulan dan tahun dari query string
 	monthStr := r.URL.Query().Get("month")
@@ -254,34 +309,36 @@ func GetAllUsersMonthlyAttendance(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Query untuk mengambil data kehadiran semua user dalam bulan & tahun tertentu
-    query := `
-        SELECT id, user_id, check_in, check_out, latitude, longitude, status
-        FROM attendance
-        WHERE EXTRACT(MONTH FROM check_in) = $1 AND EXTRACT(YEAR FROM check_in) = $2
-        ORDER BY check_in ASC`
+	// Admin melihat semua user, manager hanya bawahannya
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok || userID == "" {
+		http.Error(w, "User ID is missing", http.StatusUnauthorized)
+		return
+	}
+	all, reportIDs, err := attendanceScope(r.Context(), userID)
+	if err != nil {
+		log.Println("Error resolving attendance scope:", err)
+		http.Error(w, "Failed to resolve attendance scope", http.StatusInternalServerError)
+		return
+	}
 
-		rows, err := database.DB.Query(context.Background(), query, month, year)
-		if err != nil {
-			http.Error(w, "Failed to fetch data", http.StatusInternalServerError)
-			return
-		}
-		defer rows.Close()
-
-		var attendances []models.Attendance
-    for rows.Next() {
-        var att models.Attendance
-        err := rows.Scan(&att.ID, &att.UserID, &att.CheckIn, &att.CheckOut, &att.Latitude, &att.Longitude, &att.Status)
-        if err != nil {
-            http.Error(w, "Error scanning data", http.StatusInternalServerError)
-            return
-        }
-        attendances = append(attendances, att)
-    }
+	// Export CSV/XLSX jika diminta lewat ?format= atau header Accept
+	if format := exportFormat(r); format != formatJSON {
+		writeAttendanceExport(w, r, format, month, year, all, reportIDs)
+		return
+	}
+
+	// Rekap harian yang sama dengan export: satu baris per user per tanggal dari attendance_logs
+	recaps, err := buildMonthlyRecaps(r.Context(), month, year, all, reportIDs, "")
+	if err != nil {
+		log.Println("Error building monthly attendance:", err)
+		http.Error(w, "Failed to fetch data", http.StatusInternalServerError)
+		return
+	}
 
 	// Return response
-    w.Header().Set("Content-Type", "application/json")
-    json.NewEncoder(w).Encode(attendances)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(recapDays(recaps))
 }
 
 func GetAttendanceLogs(w http.ResponseWriter, r *http.Request) {
@@ -294,7 +351,8 @@ func GetAttendanceLogs(w http.ResponseWriter, r *http.Request) {
 
 	// Query untuk mengambil data check-in berdasarkan user_id
 	query := `
-        SELECT al.id::TEXT, al.attendance_id, al.latitude, al.longitude, al.created_at
+        SELECT al.id::TEXT, al.attendance_id::TEXT, COALESCE(al.type, ''), COALESCE(al.location_name, ''), COALESCE(al.notes, ''),
+               COALESCE(al.latitude, 0), COALESCE(al.longitude, 0), al.created_at
         FROM attendance_logs al
         JOIN attendance a ON al.attendance_id = a.id
         WHERE a.user_id = $1
@@ -313,7 +371,7 @@ func GetAttendanceLogs(w http.ResponseWriter, r *http.Request) {
 
 	for rows.Next() {
 		var logEntry models.AttendanceLog
-		err := rows.Scan(&logEntry.ID, &logEntry.AttendanceID, &logEntry.Latitude, &logEntry.Longitude, &logEntry.CreatedAt)
+		err := rows.Scan(&logEntry.ID, &logEntry.AttendanceID, &logEntry.Type, &logEntry.LocationName, &logEntry.Notes, &logEntry.Latitude, &logEntry.Longitude, &logEntry.CreatedAt)
 		if err != nil {
 			log.Println("Error scanning log data:", err)
 			http.Error(w, "Error scanning log data", http.StatusInternalServerError)
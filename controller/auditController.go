@@ -0,0 +1,215 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"absensi/database"
+	"absensi/models"
+	"absensi/utils"
+)
+
+// clientIP mengambil IP client. X-Forwarded-For hanya dipakai jika request datang dari proxy
+// terpercaya (env TRUSTED_PROXIES); hop dibaca dari kanan dan hop pertama yang bukan proxy
+// terpercaya dianggap sebagai client, karena bagian kiri header bisa diisi bebas oleh client.
+func clientIP(r *http.Request) string {
+	remote, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remote = r.RemoteAddr
+	}
+
+	proxies := utils.GetTrustedProxies()
+	forwarded := r.Header.Get("X-Forwarded-For")
+	if forwarded == "" || !proxies.Contains(remote) {
+		return remote
+	}
+
+	hops := strings.Split(forwarded, ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if net.ParseIP(hop) == nil {
+			// Hop yang tidak valid tidak bisa dipercaya, berhenti di proxy terakhir yang dikenal
+			return remote
+		}
+		if !proxies.Contains(hop) {
+			return hop
+		}
+		remote = hop
+	}
+	return remote
+}
+
+// recordAudit mencatat aksi privileged ke audit log. before/after boleh nil.
+// Kegagalan hanya dicatat di log server agar aksi yang sudah berhasil tidak dibatalkan.
+func recordAudit(r *http.Request, action, targetType, targetID string, before, after interface{}) {
+	actorID, _ := r.Context().Value("user_id").(string)
+
+	toJSON := func(v interface{}) *string {
+		if v == nil {
+			return nil
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			log.Println("Error encoding audit value:", err)
+			return nil
+		}
+		s := string(b)
+		return &s
+	}
+
+	query := `
+        INSERT INTO audit_logs (actor_id, action, target_type, target_id, before, after, ip, user_agent, created_at)
+        VALUES (NULLIF($1, '')::UUID, $2, $3, $4, $5::JSONB, $6::JSONB, $7, $8, NOW())`
+	_, err := database.DB.Exec(r.Context(), query, actorID, action, targetType, targetID,
+		toJSON(before), toJSON(after), clientIP(r), r.UserAgent())
+	if err != nil {
+		log.Println("Error writing audit log:", err, action, targetID)
+	}
+}
+
+// auditFilter menyusun klausa WHERE dari parameter query audit log
+func auditFilter(r *http.Request) (string, []interface{}, error) {
+	params := r.URL.Query()
+	conditions := []string{}
+	args := []interface{}{}
+	add := func(format string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(format, len(args)))
+	}
+
+	if v := params.Get("actor_id"); v != "" {
+		add("actor_id::TEXT = $%d", v)
+	}
+	if v := params.Get("action"); v != "" {
+		add("action = $%d", v)
+	}
+	if v := params.Get("target_type"); v != "" {
+		add("target_type = $%d", v)
+	}
+	if v := params.Get("target_id"); v != "" {
+		add("target_id = $%d", v)
+	}
+	if v := params.Get("from"); v != "" {
+		from, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			return "", nil, fmt.Errorf("Invalid from, expected YYYY-MM-DD")
+		}
+		add("created_at >= $%d", from)
+	}
+	if v := params.Get("to"); v != "" {
+		to, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			return "", nil, fmt.Errorf("Invalid to, expected YYYY-MM-DD")
+		}
+		add("created_at < $%d", to.AddDate(0, 0, 1))
+	}
+
+	if len(conditions) == 0 {
+		return "", args, nil
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args, nil
+}
+
+const auditColumns = `id::TEXT, COALESCE(actor_id::TEXT, ''), action, target_type, target_id,
+        before::TEXT, after::TEXT, COALESCE(ip, ''), COALESCE(user_agent, ''), created_at`
+
+func scanAuditLog(row rowScanner) (models.AuditLog, error) {
+	var entry models.AuditLog
+	var before, after *string
+	err := row.Scan(&entry.ID, &entry.ActorID, &entry.Action, &entry.TargetType, &entry.TargetID,
+		&before, &after, &entry.IP, &entry.UserAgent, &entry.CreatedAt)
+	if before != nil {
+		entry.Before = json.RawMessage(*before)
+	}
+	if after != nil {
+		entry.After = json.RawMessage(*after)
+	}
+	return entry, err
+}
+
+// GetAuditLogs mengembalikan audit log dengan filter actor_id, action, target_type, target_id, from, to.
+// ?format=csv mengekspor semua baris yang cocok (tanpa pagination) untuk review kepatuhan.
+func GetAuditLogs(w http.ResponseWriter, r *http.Request) {
+	where, args, err := auditFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if exportFormat(r) == formatCSV {
+		rows, err := database.DB.Query(r.Context(), "SELECT "+auditColumns+" FROM audit_logs "+where+" ORDER BY created_at ASC", args...)
+		if err != nil {
+			log.Println("Error fetching audit logs:", err)
+			http.Error(w, "Failed to fetch audit logs", http.StatusInternalServerError)
+			return
+		}
+		defer rows.Close()
+
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="audit-log.csv"`)
+		table := utils.NewCSVWriter(w)
+		table.WriteRow("id", "created_at", "actor_id", "action", "target_type", "target_id", "before", "after", "ip", "user_agent")
+		for rows.Next() {
+			entry, err := scanAuditLog(rows)
+			if err != nil {
+				log.Println("Error scanning audit log:", err)
+				return
+			}
+			err = table.WriteRow(entry.ID, entry.CreatedAt, entry.ActorID, entry.Action, entry.TargetType, entry.TargetID,
+				string(entry.Before), string(entry.After), entry.IP, entry.UserAgent)
+			if err != nil {
+				log.Println("Error writing audit CSV:", err)
+				return
+			}
+		}
+		if err := table.Close(); err != nil {
+			log.Println("Error finishing audit CSV:", err)
+		}
+		return
+	}
+
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	pageSize, err := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if err != nil || pageSize < 1 || pageSize > 200 {
+		pageSize = 50
+	}
+
+	result := models.AuditLogPage{Data: []models.AuditLog{}, Page: page, PageSize: pageSize}
+	if err := database.DB.QueryRow(r.Context(), "SELECT COUNT(*) FROM audit_logs "+where, args...).Scan(&result.Total); err != nil {
+		log.Println("Error counting audit logs:", err)
+		http.Error(w, "Failed to fetch audit logs", http.StatusInternalServerError)
+		return
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM audit_logs %s ORDER BY created_at DESC LIMIT %d OFFSET %d",
+		auditColumns, where, pageSize, (page-1)*pageSize)
+	rows, err := database.DB.Query(r.Context(), query, args...)
+	if err != nil {
+		log.Println("Error fetching audit logs:", err)
+		http.Error(w, "Failed to fetch audit logs", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry, err := scanAuditLog(rows)
+		if err != nil {
+			log.Println("Error scanning audit log:", err)
+			http.Error(w, "Error scanning data", http.StatusInternalServerError)
+			return
+		}
+		result.Data = append(result.Data, entry)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
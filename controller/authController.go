@@ -2,9 +2,9 @@ package controller
 
 import (
 	"absensi/database"
+	"absensi/jobs"
 	"absensi/models"
 	"absensi/utils"
-	"context" // Import context package
 	"log"
 	"net/http"
 
@@ -31,16 +31,14 @@ func Register(w http.ResponseWriter, r *http.Request) {
     }
     user.Password = hashedPassword
 
-    // Set role default ke "employee"
-    if user.Role == "" {
-        user.Role = "employee"
-    }
+    // Registrasi publik selalu menjadi employee, role lain hanya bisa diberikan admin
+    user.Role = models.RoleEmployee
 
     // Insert user ke database dan dapatkan ID yang dihasilkan
     var userID string
     query := `INSERT INTO users (name, email, password, role, created_at) 
               VALUES ($1, $2, $3, $4, $5) RETURNING id`
-    err = database.DB.QueryRow(context.Background(), query, user.Name, user.Email, user.Password, user.Role, time.Now()).Scan(&userID)
+    err = database.DB.QueryRow(r.Context(), query, user.Name, user.Email, user.Password, user.Role, time.Now()).Scan(&userID)
     if err != nil {
         log.Println("Database error:", err)
         http.Error(w, "User registration failed", http.StatusInternalServerError)
@@ -51,13 +49,18 @@ func Register(w http.ResponseWriter, r *http.Request) {
     queryAttendance := `INSERT INTO attendance (user_id, check_in, check_out, latitude, longitude, status, created_at) 
                     VALUES ($1, NULL, NULL, NULL, NULL, 'not checked-in', $2) RETURNING id`
     var attendanceID string
-    err = database.DB.QueryRow(context.Background(), queryAttendance, userID, time.Now()).Scan(&attendanceID)
+    err = database.DB.QueryRow(r.Context(), queryAttendance, userID, time.Now()).Scan(&attendanceID)
     if err != nil {
         log.Println("Failed to create attendance record:", err)
         http.Error(w, "Failed to create attendance record", http.StatusInternalServerError)
         return
     }
 
+    err = jobs.EnqueueWebhookEvent(r.Context(), database.DB, models.WebhookUserCreated, userWebhookData(userID, user.Name, user.Email, user.Role, "register"))
+    if err != nil {
+        log.Println("Failed to queue user webhook:", err)
+    }
+
     // Kirim response sukses
     w.WriteHeader(http.StatusCreated)
     json.NewEncoder(w).Encode(map[string]string{
@@ -82,10 +85,11 @@ func Login(w http.ResponseWriter, r *http.Request) {
 	// Cek apakah email ada di database
 	var storedPassword string
 	var userID string
-	query := `SELECT id, password FROM users WHERE email=$1`
+	var status string
+	query := `SELECT id, password, COALESCE(status, 'active') FROM users WHERE email=$1 AND deleted_at IS NULL`
 
-	row := database.DB.QueryRow(context.Background(), query, user.Email)
-	err = row.Scan(&userID, &storedPassword)
+	row := database.DB.QueryRow(r.Context(), query, user.Email)
+	err = row.Scan(&userID, &storedPassword, &status)
 	if err != nil {
 		if err == pgx.ErrNoRows { // Jika tidak ada data dengan email tersebut
 			http.Error(w, "User not found", http.StatusUnauthorized)
@@ -104,6 +108,12 @@ func Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// User yang dinonaktifkan tidak boleh login
+	if status != models.UserStatusActive {
+		http.Error(w, "Account is deactivated", http.StatusForbidden)
+		return
+	}
+
 	// Membuat token JWT
 	token, err := utils.GenerateJWT(userID)
 	if err != nil {
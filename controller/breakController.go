@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"absensi/database"
+	"absensi/events"
+	"absensi/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// StartBreak mencatat mulai istirahat. User harus sudah check-in hari ini dan belum sedang istirahat.
+func StartBreak(w http.ResponseWriter, r *http.Request) {
+	logBreak(w, r, models.LogTypeBreakStart, events.TypeBreakStart)
+}
+
+// EndBreak mencatat selesai istirahat. User harus sedang istirahat.
+func EndBreak(w http.ResponseWriter, r *http.Request) {
+	logBreak(w, r, models.LogTypeBreakEnd, events.TypeBreakEnd)
+}
+
+// logBreak menyimpan log istirahat lalu mengirim event ke live board
+func logBreak(w http.ResponseWriter, r *http.Request, logType, eventType string) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok || userID == "" {
+		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	active, err := isActiveUser(r.Context(), userID)
+	if err != nil {
+		log.Println("Error checking user status:", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if !active {
+		http.Error(w, "Account is deactivated", http.StatusForbidden)
+		return
+	}
+
+	var requestData struct {
+		Latitude  float64 `json:"latitude"`
+		Longitude float64 `json:"longitude"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	// Status hari ini ditentukan dari log check-in/check-out/istirahat terakhir
+	var attendanceID string
+	var lastType *string
+	err = database.DB.QueryRow(r.Context(), `
+        SELECT a.id::TEXT, (
+            SELECT al.type FROM attendance_logs al
+            WHERE al.attendance_id = a.id AND al.type = ANY($2) AND al.created_at >= $3
+            ORDER BY al.created_at DESC LIMIT 1
+        )
+        FROM attendance a WHERE a.user_id = $1 ORDER BY a.created_at DESC LIMIT 1`,
+		userID, []string{models.LogTypeCheckIn, models.LogTypeCheckOut, models.LogTypeBreakStart, models.LogTypeBreakEnd}, dayStart,
+	).Scan(&attendanceID, &lastType)
+	if err == pgx.ErrNoRows {
+		http.Error(w, "Attendance record not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Println("Error fetching attendance status:", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	onBreak := lastType != nil && *lastType == models.LogTypeBreakStart
+	working := lastType != nil && (*lastType == models.LogTypeCheckIn || *lastType == models.LogTypeBreakEnd)
+	if logType == models.LogTypeBreakStart && !working {
+		http.Error(w, "Must be checked in and not on a break", http.StatusConflict)
+		return
+	}
+	if logType == models.LogTypeBreakEnd && !onBreak {
+		http.Error(w, "No break in progress", http.StatusConflict)
+		return
+	}
+
+	var entry models.AttendanceLog
+	query := `
+        INSERT INTO attendance_logs (attendance_id, type, latitude, longitude, created_at) VALUES ($1, $2, $3, $4, NOW())
+        RETURNING id::TEXT, attendance_id::TEXT, type, latitude, longitude, created_at`
+	err = database.DB.QueryRow(r.Context(), query, attendanceID, logType, requestData.Latitude, requestData.Longitude).
+		Scan(&entry.ID, &entry.AttendanceID, &entry.Type, &entry.Latitude, &entry.Longitude, &entry.CreatedAt)
+	if err != nil {
+		log.Println("Error inserting break log:", err)
+		http.Error(w, "Failed to record break", http.StatusInternalServerError)
+		return
+	}
+
+	// Kirim event ke live board
+	publishAttendanceEvent(r.Context(), eventType, userID, requestData.Latitude, requestData.Longitude)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(entry)
+}
@@ -0,0 +1,146 @@
+package controller
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"absensi/database"
+	"absensi/models"
+	"absensi/utils"
+)
+
+// GetTeamDashboard mengembalikan ringkasan kehadiran tim pada satu hari (default hari ini):
+// siapa yang sudah check-in, terlambat, cuti, absen dan masih bekerja
+func GetTeamDashboard(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok || userID == "" {
+		http.Error(w, "User ID is missing", http.StatusUnauthorized)
+		return
+	}
+
+	start, err := parseDay(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	end := start.AddDate(0, 0, 1)
+
+	all, reportIDs, err := attendanceScope(r.Context(), userID)
+	if err != nil {
+		log.Println("Error resolving attendance scope:", err)
+		http.Error(w, "Failed to resolve attendance scope", http.StatusInternalServerError)
+		return
+	}
+
+	// Satu query untuk semua anggota tim: check-in pertama, event terakhir dan status cuti
+	query := `
+        SELECT u.id::TEXT, u.name, fi.first_check_in, last.type, last.created_at, last.latitude, last.longitude,
+               EXISTS (
+                   SELECT 1 FROM leave_requests lr
+                   WHERE lr.user_id = u.id AND lr.status = $5
+                     AND lr.start_date <= $7::DATE AND lr.end_date >= $7::DATE
+               ) AS on_leave
+        FROM users u
+        LEFT JOIN LATERAL (
+            SELECT MIN(al.created_at) AS first_check_in
+            FROM attendance_logs al JOIN attendance a ON al.attendance_id = a.id
+            WHERE a.user_id = u.id AND al.type = $6 AND al.created_at >= $3 AND al.created_at < $4
+        ) fi ON TRUE
+        LEFT JOIN LATERAL (
+            SELECT al.type, al.created_at, al.latitude, al.longitude
+            FROM attendance_logs al JOIN attendance a ON al.attendance_id = a.id
+            WHERE a.user_id = u.id AND al.created_at >= $3 AND al.created_at < $4
+            ORDER BY al.created_at DESC LIMIT 1
+        ) last ON TRUE
+        WHERE ($1 OR u.id::TEXT = ANY($2)) AND u.deleted_at IS NULL AND COALESCE(u.status, 'active') = 'active'
+        ORDER BY u.name ASC`
+
+	rows, err := database.DB.Query(r.Context(), query, all, reportIDs, start, end, models.LeaveStatusApproved, models.LogTypeCheckIn,
+		start.Format("2006-01-02"))
+	if err != nil {
+		log.Println("Error fetching team dashboard:", err)
+		http.Error(w, "Failed to fetch team dashboard", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	schedule := utils.GetSchedule()
+	workday := schedule.IsWorkday(start)
+	dashboard := models.TeamDashboard{
+		Date:    start.Format("2006-01-02"),
+		Members: []models.TeamMemberStatus{},
+	}
+
+	for rows.Next() {
+		var member models.TeamMemberStatus
+		var lastType *string
+		var onLeave bool
+
+		err := rows.Scan(&member.UserID, &member.Name, &member.FirstCheckIn, &lastType, &member.LastEventAt,
+			&member.LastLatitude, &member.LastLongitude, &onLeave)
+		if err != nil {
+			log.Println("Error scanning team member:", err)
+			http.Error(w, "Error scanning data", http.StatusInternalServerError)
+			return
+		}
+		if lastType != nil {
+			member.LastEventType = *lastType
+		}
+
+		member.Status = memberDayStatus(member, onLeave, workday)
+		if member.FirstCheckIn != nil && workday {
+			member.LateMinutes = schedule.LateMinutes(member.FirstCheckIn.In(time.Local))
+			member.Late = member.LateMinutes > 0
+		}
+
+		dashboard.Counts.Total++
+		if member.FirstCheckIn != nil {
+			dashboard.Counts.CheckedIn++
+		}
+		if member.Late {
+			dashboard.Counts.Late++
+		}
+		switch member.Status {
+		case models.DayStatusOnLeave:
+			dashboard.Counts.OnLeave++
+		case models.DayStatusAbsent:
+			dashboard.Counts.Absent++
+		case models.DayStatusStillWorking:
+			dashboard.Counts.StillWorking++
+		case models.DayStatusCheckedOut:
+			dashboard.Counts.CheckedOut++
+		case models.DayStatusOff:
+			dashboard.Counts.Off++
+		}
+
+		dashboard.Members = append(dashboard.Members, member)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Println("Error iterating rows:", err)
+		http.Error(w, "Error processing team dashboard", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dashboard)
+}
+
+// memberDayStatus menentukan status harian berdasarkan check-in pertama dan event terakhir.
+// Di luar hari kerja user yang tidak check-in dianggap libur, bukan absen.
+func memberDayStatus(member models.TeamMemberStatus, onLeave, workday bool) string {
+	switch {
+	case member.FirstCheckIn == nil && onLeave:
+		return models.DayStatusOnLeave
+	case member.FirstCheckIn == nil && !workday:
+		return models.DayStatusOff
+	case member.FirstCheckIn == nil:
+		return models.DayStatusAbsent
+	case member.LastEventType == models.LogTypeCheckOut:
+		return models.DayStatusCheckedOut
+	default:
+		return models.DayStatusStillWorking
+	}
+}
@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"absensi/models"
+)
+
+func TestMemberDayStatus(t *testing.T) {
+	checkIn := time.Date(2026, 10, 17, 8, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name    string
+		member  models.TeamMemberStatus
+		onLeave bool
+		workday bool
+		want    string
+	}{
+		{"belum check-in di hari kerja", models.TeamMemberStatus{}, false, true, models.DayStatusAbsent},
+		{"belum check-in di akhir pekan", models.TeamMemberStatus{}, false, false, models.DayStatusOff},
+		{"cuti di akhir pekan", models.TeamMemberStatus{}, true, false, models.DayStatusOnLeave},
+		{"lembur di akhir pekan", models.TeamMemberStatus{FirstCheckIn: &checkIn, LastEventType: models.LogTypeCheckIn}, false, false, models.DayStatusStillWorking},
+		{"sudah check-out", models.TeamMemberStatus{FirstCheckIn: &checkIn, LastEventType: models.LogTypeCheckOut}, false, true, models.DayStatusCheckedOut},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := memberDayStatus(tt.member, tt.onLeave, tt.workday); got != tt.want {
+				t.Errorf("memberDayStatus() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"absensi/database"
+	"absensi/models"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterDevice mendaftarkan token FCM perangkat user yang login. Token yang sudah ada
+// dipindahkan ke user ini (misalnya perangkat yang ganti akun).
+func RegisterDevice(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok || userID == "" {
+		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	var data struct {
+		Token    string `json:"token"`
+		Platform string `json:"platform"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	data.Token = strings.TrimSpace(data.Token)
+	data.Platform = strings.ToLower(strings.TrimSpace(data.Platform))
+	if data.Token == "" {
+		http.Error(w, "Token is required", http.StatusBadRequest)
+		return
+	}
+	if data.Platform != "android" && data.Platform != "ios" && data.Platform != "web" {
+		http.Error(w, "Platform must be android, ios or web", http.StatusBadRequest)
+		return
+	}
+
+	query := `
+        INSERT INTO device_tokens (user_id, token, platform, created_at, last_seen_at)
+        VALUES ($1, $2, $3, NOW(), NOW())
+        ON CONFLICT (token) DO UPDATE SET user_id = EXCLUDED.user_id, platform = EXCLUDED.platform, last_seen_at = NOW()
+        RETURNING id::TEXT, token, platform, created_at, last_seen_at`
+	var device models.DeviceToken
+	err := database.DB.QueryRow(r.Context(), query, userID, data.Token, data.Platform).Scan(&device.ID, &device.Token,
+		&device.Platform, &device.CreatedAt, &device.LastSeenAt)
+	if err != nil {
+		log.Println("Error registering device:", err)
+		http.Error(w, "Failed to register device", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(device)
+}
+
+// GetMyDevices mengembalikan perangkat yang terdaftar untuk user yang login
+func GetMyDevices(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok || userID == "" {
+		http.Error(w, "User ID is missing", http.StatusUnauthorized)
+		return
+	}
+
+	rows, err := database.DB.Query(r.Context(), `
+        SELECT id::TEXT, token, platform, created_at, last_seen_at FROM device_tokens
+        WHERE user_id = $1 ORDER BY last_seen_at DESC`, userID)
+	if err != nil {
+		log.Println("Error fetching devices:", err)
+		http.Error(w, "Failed to fetch devices", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	devices := []models.DeviceToken{}
+	for rows.Next() {
+		var device models.DeviceToken
+		if err := rows.Scan(&device.ID, &device.Token, &device.Platform, &device.CreatedAt, &device.LastSeenAt); err != nil {
+			log.Println("Error scanning device:", err)
+			http.Error(w, "Error scanning data", http.StatusInternalServerError)
+			return
+		}
+		devices = append(devices, device)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(devices)
+}
+
+// DeleteDevice menghapus perangkat milik user yang login, misalnya saat logout
+func DeleteDevice(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok || userID == "" {
+		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	tag, err := database.DB.Exec(r.Context(), "DELETE FROM device_tokens WHERE id = $1 AND user_id = $2", mux.Vars(r)["id"], userID)
+	if err != nil {
+		log.Println("Error deleting device:", err)
+		http.Error(w, "Failed to delete device", http.StatusInternalServerError)
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		http.Error(w, "Device not found", http.StatusNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Device removed"})
+}
@@ -0,0 +1,163 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"absensi/database"
+	"absensi/models"
+	"absensi/utils"
+)
+
+// Format export yang didukung
+const (
+	formatJSON = "json"
+	formatCSV  = "csv"
+	formatXLSX = "xlsx"
+)
+
+const xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// exportFormat menentukan format respon dari parameter ?format= atau header Accept
+func exportFormat(r *http.Request) string {
+	switch strings.ToLower(r.URL.Query().Get("format")) {
+	case formatCSV:
+		return formatCSV
+	case formatXLSX:
+		return formatXLSX
+	case formatJSON:
+		return formatJSON
+	}
+
+	accept := r.Header.Get("Accept")
+	switch {
+	case strings.Contains(accept, "text/csv"):
+		return formatCSV
+	case strings.Contains(accept, xlsxContentType):
+		return formatXLSX
+	}
+	return formatJSON
+}
+
+// fetchDailyAttendance membaca rekap harian dalam rentang [from, to) dan memanggil fn untuk setiap baris
+// tanpa menampung seluruh hasil di memori. Jika all false, hanya user di userIDs yang diambil.
+// Tanggal dikelompokkan menurut zona waktu from agar check-in dini hari tidak masuk ke hari sebelumnya.
+func fetchDailyAttendance(ctx context.Context, from, to time.Time, all bool, userIDs []string, fn func(models.DailyAttendance) error) error {
+	query := `
+        SELECT u.id::TEXT, u.name, COALESCE(d.name, ''), DATE(al.created_at AT TIME ZONE $7) AS day,
+               MIN(al.created_at) FILTER (WHERE al.type = $5),
+               MAX(al.created_at) FILTER (WHERE al.type = $6),
+               (ARRAY_AGG(al.latitude ORDER BY al.created_at) FILTER (WHERE al.type = $5))[1],
+               (ARRAY_AGG(al.longitude ORDER BY al.created_at) FILTER (WHERE al.type = $5))[1]
+        FROM attendance_logs al
+        JOIN attendance a ON al.attendance_id = a.id
+        JOIN users u ON u.id = a.user_id
+        LEFT JOIN departments d ON d.id = u.department_id
+        WHERE al.created_at >= $1 AND al.created_at < $2
+          AND ($3 OR u.id::TEXT = ANY($4))
+        GROUP BY u.id, u.name, d.name, day
+        ORDER BY u.name ASC, day ASC`
+
+	rows, err := database.DB.Query(ctx, query, from, to, all, userIDs, models.LogTypeCheckIn, models.LogTypeCheckOut, utils.PGTimeZone(from))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	schedule := utils.GetSchedule()
+	for rows.Next() {
+		var day models.DailyAttendance
+		err := rows.Scan(&day.UserID, &day.Name, &day.DepartmentName, &day.Date,
+			&day.CheckIn, &day.CheckOut, &day.Latitude, &day.Longitude)
+		if err != nil {
+			return err
+		}
+
+		day.Status = models.DailyStatusPresent
+		if day.CheckIn != nil {
+			day.LateMinutes = schedule.LateMinutes(day.CheckIn.In(time.Local))
+			if day.LateMinutes > 0 {
+				day.Status = models.DailyStatusLate
+			}
+		}
+		if day.CheckIn != nil && day.CheckOut != nil && day.CheckOut.After(*day.CheckIn) {
+			hours := day.CheckOut.Sub(*day.CheckIn).Hours()
+			day.WorkedHours = math.Round(hours*100) / 100
+
+			// Lembur dihitung dari jam kerja di luar jadwal selesai
+			if end := schedule.EndOn(day.CheckIn.In(time.Local)); day.CheckOut.After(end) {
+				day.OvertimeHours = math.Round(day.CheckOut.Sub(end).Hours()*100) / 100
+			}
+		} else if day.CheckIn != nil {
+			day.Status = models.DailyStatusNoCheckOut
+		}
+
+		if err := fn(day); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// recapDays meratakan rekap menjadi satu baris per user per tanggal, urut per user lalu tanggal
+func recapDays(recaps []models.MonthlyRecap) []models.DailyAttendance {
+	days := []models.DailyAttendance{}
+	for _, recap := range recaps {
+		days = append(days, recap.Days...)
+	}
+	return days
+}
+
+// writeAttendanceExport menulis rekap harian satu bulan sebagai file CSV atau XLSX. Barisnya sama dengan
+// respon JSON: satu baris per user per tanggal, termasuk hari absen, cuti dan libur (lihat buildRecaps).
+func writeAttendanceExport(w http.ResponseWriter, r *http.Request, format string, month, year int, all bool, userIDs []string) {
+	recaps, err := buildMonthlyRecaps(r.Context(), month, year, all, userIDs, "")
+	if err != nil {
+		log.Println("Error building attendance export:", err)
+		http.Error(w, "Failed to create export", http.StatusInternalServerError)
+		return
+	}
+	filename := fmt.Sprintf("absensi-%04d-%02d.%s", year, month, format)
+
+	var table utils.TableWriter
+	if format == formatXLSX {
+		w.Header().Set("Content-Type", xlsxContentType)
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+		table, err = utils.NewXLSXWriter(w, fmt.Sprintf("%04d-%02d", year, month))
+		if err != nil {
+			log.Println("Error creating XLSX export:", err)
+			http.Error(w, "Failed to create export", http.StatusInternalServerError)
+			return
+		}
+	} else {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+		table = utils.NewCSVWriter(w)
+	}
+
+	// Header sudah terkirim, jika menulis gagal tidak bisa lagi mengirim status error
+	err = table.WriteRow("user_id", "name", "department", "date", "check_in", "check_out", "status",
+		"late_minutes", "worked_hours", "latitude", "longitude", "leave_type", "overtime_hours")
+	if err != nil {
+		log.Println("Error writing attendance export:", err)
+		return
+	}
+	for _, day := range recapDays(recaps) {
+		err := table.WriteRow(day.UserID, day.Name, day.DepartmentName, day.Date.Format("2006-01-02"),
+			day.CheckIn, day.CheckOut, day.Status, day.LateMinutes, day.WorkedHours, day.Latitude, day.Longitude,
+			day.LeaveType, day.OvertimeHours)
+		if err != nil {
+			log.Println("Error writing attendance export:", err)
+			return
+		}
+	}
+
+	if err := table.Close(); err != nil {
+		log.Println("Error finishing attendance export:", err)
+	}
+}
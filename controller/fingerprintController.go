@@ -0,0 +1,345 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"absensi/database"
+	"absensi/models"
+	"absensi/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// maxImportSize membatasi ukuran file import
+const maxImportSize = 32 << 20
+
+func GetFingerprintMappings(w http.ResponseWriter, r *http.Request) {
+	query := `
+        SELECT device_id, device_user_id, user_id::TEXT, created_at FROM fingerprint_mappings
+        WHERE ($1 = '' OR device_id = $1)
+        ORDER BY device_id, device_user_id`
+
+	rows, err := database.DB.Query(r.Context(), query, r.URL.Query().Get("device_id"))
+	if err != nil {
+		log.Println("Error fetching fingerprint mappings:", err)
+		http.Error(w, "Failed to fetch mappings", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	mappings := []models.FingerprintMapping{}
+	for rows.Next() {
+		var m models.FingerprintMapping
+		if err := rows.Scan(&m.DeviceID, &m.DeviceUserID, &m.UserID, &m.CreatedAt); err != nil {
+			log.Println("Error scanning fingerprint mapping:", err)
+			http.Error(w, "Error scanning data", http.StatusInternalServerError)
+			return
+		}
+		mappings = append(mappings, m)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(mappings)
+}
+
+// SaveFingerprintMapping membuat atau mengganti pemetaan user mesin ke user aplikasi
+func SaveFingerprintMapping(w http.ResponseWriter, r *http.Request) {
+	var m models.FingerprintMapping
+	if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	m.DeviceID = strings.TrimSpace(m.DeviceID)
+	m.DeviceUserID = strings.TrimSpace(m.DeviceUserID)
+	if m.DeviceUserID == "" || m.UserID == "" {
+		http.Error(w, "device_user_id and user_id are required", http.StatusBadRequest)
+		return
+	}
+
+	query := `
+        INSERT INTO fingerprint_mappings (device_id, device_user_id, user_id, created_at)
+        VALUES ($1, $2, $3, NOW())
+        ON CONFLICT (device_id, device_user_id) DO UPDATE SET user_id = EXCLUDED.user_id
+        RETURNING created_at`
+	if err := database.DB.QueryRow(r.Context(), query, m.DeviceID, m.DeviceUserID, m.UserID).Scan(&m.CreatedAt); err != nil {
+		log.Println("Error saving fingerprint mapping:", err)
+		http.Error(w, "Failed to save mapping", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, models.AuditMappingSaved, "fingerprint_mapping", m.DeviceID+"/"+m.DeviceUserID, nil, m)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(m)
+}
+
+func DeleteFingerprintMapping(w http.ResponseWriter, r *http.Request) {
+	deviceUserID := mux.Vars(r)["device_user_id"]
+
+	tag, err := database.DB.Exec(r.Context(), "DELETE FROM fingerprint_mappings WHERE device_id = $1 AND device_user_id = $2",
+		r.URL.Query().Get("device_id"), deviceUserID)
+	if err != nil {
+		log.Println("Error deleting fingerprint mapping:", err)
+		http.Error(w, "Failed to delete mapping", http.StatusInternalServerError)
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		http.Error(w, "Mapping not found", http.StatusNotFound)
+		return
+	}
+
+	recordAudit(r, models.AuditMappingDeleted, "fingerprint_mapping", r.URL.Query().Get("device_id")+"/"+deviceUserID, nil, nil)
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Mapping deleted"})
+}
+
+// readImportFile mengambil isi file dari multipart field "file" atau langsung dari body
+func readImportFile(r *http.Request) ([]byte, string, error) {
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(maxImportSize); err != nil {
+			return nil, "", err
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			return nil, "", err
+		}
+		defer file.Close()
+		data, err := io.ReadAll(io.LimitReader(file, maxImportSize))
+		return data, header.Filename, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r.Body, maxImportSize))
+	return data, "", err
+}
+
+// ImportFingerprintPunches mengimpor file attlog.dat / CSV dari mesin fingerprint menjadi check-in/check-out.
+// Parameter: device_id, format (attlog|csv), latitude & longitude lokasi mesin, dry_run=true untuk simulasi.
+func ImportFingerprintPunches(w http.ResponseWriter, r *http.Request) {
+	data, filename, err := readImportFile(r)
+	if err != nil {
+		http.Error(w, "Failed to read import file", http.StatusBadRequest)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		switch {
+		case strings.EqualFold(filepath.Ext(filename), ".csv"):
+			format = "csv"
+		case strings.EqualFold(filepath.Ext(filename), ".dat"), bytes.Contains(data, []byte("\t")):
+			format = "attlog"
+		default:
+			format = "csv"
+		}
+	}
+
+	var punches []utils.Punch
+	if format == "attlog" {
+		punches, err = utils.ParseAttlog(bytes.NewReader(data))
+	} else {
+		punches, err = utils.ParsePunchCSV(bytes.NewReader(data))
+	}
+	if err != nil {
+		http.Error(w, "Failed to parse import file", http.StatusBadRequest)
+		return
+	}
+
+	deviceID := r.URL.Query().Get("device_id")
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+
+	// Koordinat mesin (opsional), NULL jika tidak diisi
+	var latitude, longitude *float64
+	if v, err := strconv.ParseFloat(r.URL.Query().Get("latitude"), 64); err == nil {
+		latitude = &v
+	}
+	if v, err := strconv.ParseFloat(r.URL.Query().Get("longitude"), 64); err == nil {
+		longitude = &v
+	}
+
+	// Pemetaan user mesin ke user aplikasi
+	mapping := map[string]string{}
+	rows, err := database.DB.Query(r.Context(), "SELECT device_user_id, user_id::TEXT FROM fingerprint_mappings WHERE device_id = $1", deviceID)
+	if err != nil {
+		log.Println("Error fetching fingerprint mappings:", err)
+		http.Error(w, "Failed to fetch mappings", http.StatusInternalServerError)
+		return
+	}
+	for rows.Next() {
+		var deviceUserID, userID string
+		if err := rows.Scan(&deviceUserID, &userID); err != nil {
+			rows.Close()
+			log.Println("Error scanning fingerprint mapping:", err)
+			http.Error(w, "Error scanning data", http.StatusInternalServerError)
+			return
+		}
+		mapping[deviceUserID] = userID
+	}
+	rows.Close()
+
+	tx, err := database.DB.Begin(r.Context())
+	if err != nil {
+		log.Println("Error starting import transaction:", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	report := models.ImportReport{DryRun: dryRun, Lines: make([]models.ImportLine, len(punches))}
+
+	// Proses secara kronologis agar punch tanpa status bisa ditentukan masuk/pulang
+	order := make([]int, len(punches))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return punches[order[a]].Time.Before(punches[order[b]].Time) })
+
+	attendanceIDs := map[string]string{}
+	checkedInDays := map[string]bool{}
+	seen := map[string]bool{}
+
+	for _, i := range order {
+		punch := punches[i]
+		line := models.ImportLine{Line: punch.Line, DeviceUserID: punch.DeviceUserID}
+
+		if punch.Err != nil {
+			line.Result, line.Reason = models.ImportResultInvalid, punch.Err.Error()
+			report.Lines[i] = line
+			continue
+		}
+		ts := punch.Time
+		line.Timestamp = &ts
+
+		if punch.Direction == utils.PunchSkip {
+			line.Result, line.Reason = models.ImportResultSkipped, "break punch ignored"
+			report.Lines[i] = line
+			continue
+		}
+
+		userID, ok := mapping[punch.DeviceUserID]
+		if !ok {
+			line.Result, line.Reason = models.ImportResultUnmatched, "no mapping for device user"
+			report.Lines[i] = line
+			continue
+		}
+		line.UserID = userID
+
+		// Punch pertama pada hari itu dianggap check-in, selanjutnya check-out
+		dayKey := userID + "|" + ts.Format("2006-01-02")
+		logType := models.LogTypeCheckOut
+		switch punch.Direction {
+		case utils.PunchIn:
+			logType = models.LogTypeCheckIn
+		case utils.PunchAuto:
+			if !checkedInDays[dayKey] {
+				var exists bool
+				err := tx.QueryRow(r.Context(), `
+                    SELECT EXISTS (
+                        SELECT 1 FROM attendance_logs al JOIN attendance a ON al.attendance_id = a.id
+                        WHERE a.user_id = $1 AND al.type = $2 AND DATE(al.created_at AT TIME ZONE $4) = $3::DATE
+                    )`, userID, models.LogTypeCheckIn, ts.Format("2006-01-02"), utils.PGTimeZone(ts)).Scan(&exists)
+				if err != nil {
+					log.Println("Error checking existing check-in:", err)
+					http.Error(w, "Database error", http.StatusInternalServerError)
+					return
+				}
+				if !exists {
+					logType = models.LogTypeCheckIn
+				}
+			}
+		}
+		if logType == models.LogTypeCheckIn {
+			checkedInDays[dayKey] = true
+		}
+		line.Type = logType
+
+		// Deduplikasi di dalam file maupun dengan data yang sudah ada
+		punchKey := userID + "|" + logType + "|" + ts.String()
+		if seen[punchKey] {
+			line.Result, line.Reason = models.ImportResultSkipped, "duplicate punch in file"
+			report.Lines[i] = line
+			continue
+		}
+		seen[punchKey] = true
+
+		var duplicate bool
+		err = tx.QueryRow(r.Context(), `
+            SELECT EXISTS (
+                SELECT 1 FROM attendance_logs al JOIN attendance a ON al.attendance_id = a.id
+                WHERE a.user_id = $1 AND al.type = $2 AND al.created_at = $3
+            )`, userID, logType, ts).Scan(&duplicate)
+		if err != nil {
+			log.Println("Error checking duplicate punch:", err)
+			http.Error(w, "Database error", http.StatusInternalServerError)
+			return
+		}
+		if duplicate {
+			line.Result, line.Reason = models.ImportResultSkipped, "already imported"
+			report.Lines[i] = line
+			continue
+		}
+
+		attendanceID, ok := attendanceIDs[userID]
+		if !ok {
+			err = tx.QueryRow(r.Context(), `SELECT id FROM attendance WHERE user_id = $1 ORDER BY created_at DESC LIMIT 1`, userID).Scan(&attendanceID)
+			if err != nil {
+				err = tx.QueryRow(r.Context(), `INSERT INTO attendance (user_id, created_at) VALUES ($1, NOW()) RETURNING id`, userID).Scan(&attendanceID)
+			}
+			if err != nil {
+				log.Println("Error resolving attendance record:", err)
+				http.Error(w, "Failed to create attendance record", http.StatusInternalServerError)
+				return
+			}
+			attendanceIDs[userID] = attendanceID
+		}
+
+		_, err = tx.Exec(r.Context(), `
+            INSERT INTO attendance_logs (attendance_id, type, source, latitude, longitude, created_at)
+            VALUES ($1, $2, 'fingerprint', $3, $4, $5)`, attendanceID, logType, latitude, longitude, ts)
+		if err != nil {
+			log.Println("Error inserting fingerprint punch:", err)
+			http.Error(w, "Failed to import punches", http.StatusInternalServerError)
+			return
+		}
+
+		line.Result = models.ImportResultImported
+		report.Lines[i] = line
+	}
+
+	for _, line := range report.Lines {
+		report.Total++
+		switch line.Result {
+		case models.ImportResultImported:
+			report.Imported++
+		case models.ImportResultSkipped:
+			report.Skipped++
+		case models.ImportResultUnmatched:
+			report.Unmatched++
+		case models.ImportResultInvalid:
+			report.Invalid++
+		}
+	}
+
+	if !dryRun {
+		if err := tx.Commit(r.Context()); err != nil {
+			log.Println("Error committing import:", err)
+			http.Error(w, "Failed to import punches", http.StatusInternalServerError)
+			return
+		}
+
+		recordAudit(r, models.AuditPunchesImported, "fingerprint_device", deviceID, nil, map[string]int{
+			"imported": report.Imported, "skipped": report.Skipped, "unmatched": report.Unmatched, "invalid": report.Invalid,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
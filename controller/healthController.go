@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"absensi/database"
+)
+
+// HealthCheck memeriksa koneksi database dan mengembalikan statistik pool koneksi.
+// Mengembalikan 503 jika database tidak bisa dijangkau.
+func HealthCheck(w http.ResponseWriter, r *http.Request) {
+	stat := database.DB.Stat()
+	result := map[string]interface{}{
+		"status":   "ok",
+		"database": "ok",
+		"pool": map[string]int32{
+			"total":    stat.TotalConns(),
+			"idle":     stat.IdleConns(),
+			"acquired": stat.AcquiredConns(),
+			"max":      stat.MaxConns(),
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := database.Ping(r.Context()); err != nil {
+		log.Println("Database health check failed:", err)
+		result["status"] = "unavailable"
+		result["database"] = "unreachable"
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"absensi/models"
+)
+
+// rowScanner dipenuhi oleh pgx.Row maupun pgx.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// parseMonthYear membaca parameter month & year dari query string
+func parseMonthYear(r *http.Request) (int, int, error) {
+	monthStr := r.URL.Query().Get("month")
+	yearStr := r.URL.Query().Get("year")
+
+	if monthStr == "" || yearStr == "" {
+		return 0, 0, errors.New("Month and year are required")
+	}
+
+	month, err := strconv.Atoi(monthStr)
+	if err != nil || month < 1 || month > 12 {
+		return 0, 0, errors.New("Invalid month")
+	}
+
+	year, err := strconv.Atoi(yearStr)
+	if err != nil || year < 2000 || year > 2100 {
+		return 0, 0, errors.New("Invalid year")
+	}
+
+	return month, year, nil
+}
+
+// parseDay membaca parameter date (YYYY-MM-DD) dan mengembalikan awal hari tersebut, default hari ini
+func parseDay(r *http.Request) (time.Time, error) {
+	day := time.Now()
+	if dateStr := r.URL.Query().Get("date"); dateStr != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", dateStr, time.Local)
+		if err != nil {
+			return time.Time{}, errors.New("Invalid date, expected YYYY-MM-DD")
+		}
+		day = parsed
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.Local), nil
+}
+
+// scanAttendance membaca satu baris attendance
+// (id, user_id, check_in, check_out, latitude, longitude, status), kolom NULL dibiarkan zero value.
+// Kolom tambahan di awal SELECT bisa dibaca lewat leading.
+func scanAttendance(row rowScanner, leading ...interface{}) (models.Attendance, error) {
+	var att models.Attendance
+	var checkIn, checkOut *time.Time
+	var latitude, longitude *float64
+	var status *string
+
+	dest := append(leading, &att.ID, &att.UserID, &checkIn, &checkOut, &latitude, &longitude, &status)
+	err := row.Scan(dest...)
+	if err != nil {
+		return att, err
+	}
+
+	if checkIn != nil {
+		att.CheckIn = *checkIn
+	}
+	if checkOut != nil {
+		att.CheckOut = *checkOut
+	}
+	if latitude != nil {
+		att.Latitude = *latitude
+	}
+	if longitude != nil {
+		att.Longitude = *longitude
+	}
+	if status != nil {
+		att.Status = *status
+	}
+	return att, nil
+}
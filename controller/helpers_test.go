@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeRow meniru pgx.Row: nilai nil berarti kolom NULL, yang hanya boleh dibaca ke pointer
+type fakeRow []interface{}
+
+func (f fakeRow) Scan(dest ...interface{}) error {
+	if len(dest) != len(f) {
+		return fmt.Errorf("expected %d destinations, got %d", len(f), len(dest))
+	}
+	for i, d := range dest {
+		target := reflect.ValueOf(d).Elem()
+		if f[i] == nil {
+			if target.Kind() != reflect.Pointer {
+				return fmt.Errorf("cannot scan NULL into %s", target.Type())
+			}
+			target.Set(reflect.Zero(target.Type()))
+			continue
+		}
+		value := reflect.ValueOf(f[i])
+		if target.Kind() == reflect.Pointer {
+			ptr := reflect.New(target.Type().Elem())
+			ptr.Elem().Set(value)
+			value = ptr
+		}
+		target.Set(value)
+	}
+	return nil
+}
+
+func TestScanAttendance(t *testing.T) {
+	checkIn := time.Date(2026, 10, 19, 8, 1, 0, 0, time.UTC)
+
+	t.Run("null coordinates after retention", func(t *testing.T) {
+		row := fakeRow{"a1", "u1", checkIn, nil, nil, nil, nil}
+		att, err := scanAttendance(row)
+		if err != nil {
+			t.Fatalf("scanAttendance() error = %v", err)
+		}
+		if att.ID != "a1" || att.UserID != "u1" || !att.CheckIn.Equal(checkIn) {
+			t.Errorf("scanAttendance() = %+v", att)
+		}
+		if att.Latitude != 0 || att.Longitude != 0 || !att.CheckOut.IsZero() || att.Status != "" {
+			t.Errorf("NULL columns should be zero values, got %+v", att)
+		}
+	})
+
+	t.Run("values and leading columns", func(t *testing.T) {
+		var deptID, deptName string
+		row := fakeRow{"d1", "Engineering", "a1", "u1", checkIn, checkIn.Add(9 * time.Hour), -6.2, 106.8, "present"}
+		att, err := scanAttendance(row, &deptID, &deptName)
+		if err != nil {
+			t.Fatalf("scanAttendance() error = %v", err)
+		}
+		if deptID != "d1" || deptName != "Engineering" {
+			t.Errorf("leading columns = %q, %q", deptID, deptName)
+		}
+		if att.Latitude != -6.2 || att.Longitude != 106.8 || att.Status != "present" || !att.CheckOut.Equal(checkIn.Add(9*time.Hour)) {
+			t.Errorf("scanAttendance() = %+v", att)
+		}
+	})
+}
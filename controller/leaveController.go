@@ -0,0 +1,263 @@
+package controller
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"absensi/database"
+	"absensi/jobs"
+	"absensi/models"
+	"absensi/notify"
+
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+)
+
+const leaveColumns = `id::TEXT, user_id::TEXT, type, start_date, end_date, COALESCE(reason, ''), status,
+        reviewed_by::TEXT, reviewed_at, created_at`
+
+func scanLeave(row rowScanner) (models.LeaveRequest, error) {
+	var leave models.LeaveRequest
+	err := row.Scan(&leave.ID, &leave.UserID, &leave.Type, &leave.StartDate, &leave.EndDate, &leave.Reason,
+		&leave.Status, &leave.ReviewedBy, &leave.ReviewedAt, &leave.CreatedAt)
+	return leave, err
+}
+
+// CreateLeaveRequest mengajukan cuti untuk user yang login
+func CreateLeaveRequest(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok || userID == "" {
+		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	var data struct {
+		Type      string `json:"type"`
+		StartDate string `json:"start_date"`
+		EndDate   string `json:"end_date"`
+		Reason    string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	start, err := time.Parse("2006-01-02", data.StartDate)
+	if err != nil {
+		http.Error(w, "Invalid start_date, expected YYYY-MM-DD", http.StatusBadRequest)
+		return
+	}
+	end, err := time.Parse("2006-01-02", data.EndDate)
+	if err != nil || end.Before(start) {
+		http.Error(w, "Invalid end_date, expected YYYY-MM-DD not before start_date", http.StatusBadRequest)
+		return
+	}
+
+	data.Type = strings.TrimSpace(data.Type)
+	if data.Type == "" {
+		data.Type = "annual"
+	}
+
+	query := `
+        INSERT INTO leave_requests (user_id, type, start_date, end_date, reason, status, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, NOW())
+        RETURNING ` + leaveColumns
+	leave, err := scanLeave(database.DB.QueryRow(r.Context(), query, userID, data.Type, start, end, data.Reason, models.LeaveStatusPending))
+	if err != nil {
+		log.Println("Error creating leave request:", err)
+		http.Error(w, "Failed to create leave request", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(leave)
+}
+
+// GetMyLeaveRequests mengembalikan riwayat pengajuan cuti user yang login
+func GetMyLeaveRequests(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok || userID == "" {
+		http.Error(w, "User ID is missing", http.StatusUnauthorized)
+		return
+	}
+
+	rows, err := database.DB.Query(r.Context(), `SELECT `+leaveColumns+` FROM leave_requests WHERE user_id = $1 ORDER BY start_date DESC`, userID)
+	if err != nil {
+		log.Println("Error fetching leave requests:", err)
+		http.Error(w, "Failed to fetch leave requests", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	leaves := []models.LeaveRequest{}
+	for rows.Next() {
+		leave, err := scanLeave(rows)
+		if err != nil {
+			log.Println("Error scanning leave request:", err)
+			http.Error(w, "Error scanning data", http.StatusInternalServerError)
+			return
+		}
+		leaves = append(leaves, leave)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(leaves)
+}
+
+// GetTeamLeaveRequests mengembalikan pengajuan cuti bawahan, bisa difilter dengan ?status=
+func GetTeamLeaveRequests(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok || userID == "" {
+		http.Error(w, "User ID is missing", http.StatusUnauthorized)
+		return
+	}
+
+	all, reportIDs, err := attendanceScope(r.Context(), userID)
+	if err != nil {
+		log.Println("Error resolving attendance scope:", err)
+		http.Error(w, "Failed to resolve attendance scope", http.StatusInternalServerError)
+		return
+	}
+
+	query := `
+        SELECT ` + leaveColumns + `
+        FROM leave_requests
+        WHERE ($1 OR user_id::TEXT = ANY($2)) AND ($3 = '' OR status = $3)
+        ORDER BY created_at DESC`
+
+	rows, err := database.DB.Query(r.Context(), query, all, reportIDs, r.URL.Query().Get("status"))
+	if err != nil {
+		log.Println("Error fetching team leave requests:", err)
+		http.Error(w, "Failed to fetch leave requests", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	leaves := []models.LeaveRequest{}
+	for rows.Next() {
+		leave, err := scanLeave(rows)
+		if err != nil {
+			log.Println("Error scanning leave request:", err)
+			http.Error(w, "Error scanning data", http.StatusInternalServerError)
+			return
+		}
+		leaves = append(leaves, leave)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(leaves)
+}
+
+// DecideLeaveRequest menyetujui atau menolak pengajuan cuti milik bawahan
+func DecideLeaveRequest(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok || userID == "" {
+		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+	leaveID := mux.Vars(r)["id"]
+
+	var data struct {
+		Status string `json:"status"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	if data.Status != models.LeaveStatusApproved && data.Status != models.LeaveStatusRejected {
+		http.Error(w, "Status must be approved or rejected", http.StatusBadRequest)
+		return
+	}
+
+	var ownerID, currentStatus string
+	err := database.DB.QueryRow(r.Context(), "SELECT user_id::TEXT, status FROM leave_requests WHERE id = $1", leaveID).Scan(&ownerID, &currentStatus)
+	if err != nil {
+		http.Error(w, "Leave request not found", http.StatusNotFound)
+		return
+	}
+
+	allowed, err := isInScope(r.Context(), userID, ownerID)
+	if err != nil {
+		log.Println("Error resolving attendance scope:", err)
+		http.Error(w, "Failed to resolve attendance scope", http.StatusInternalServerError)
+		return
+	}
+	if !allowed || ownerID == userID {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	if currentStatus != models.LeaveStatusPending {
+		http.Error(w, "Leave request has already been decided", http.StatusConflict)
+		return
+	}
+
+	// Keputusan dan notifikasi ke karyawan disimpan dalam satu transaksi
+	tx, err := database.DB.Begin(r.Context())
+	if err != nil {
+		log.Println("Error starting leave transaction:", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	query := `
+        UPDATE leave_requests SET status = $1, reviewed_by = $2, reviewed_at = NOW()
+        WHERE id = $3 AND status = $4
+        RETURNING ` + leaveColumns
+	leave, err := scanLeave(tx.QueryRow(r.Context(), query, data.Status, userID, leaveID, models.LeaveStatusPending))
+	if err == pgx.ErrNoRows {
+		// Sudah diputuskan oleh reviewer lain di antara pengecekan dan update
+		http.Error(w, "Leave request has already been decided", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Println("Error deciding leave request:", err)
+		http.Error(w, "Failed to update leave request", http.StatusInternalServerError)
+		return
+	}
+
+	var ownerName, reviewerName string
+	err = tx.QueryRow(r.Context(), `
+        SELECT (SELECT name FROM users WHERE id = $1), (SELECT name FROM users WHERE id = $2)`, ownerID, userID).Scan(&ownerName, &reviewerName)
+	if err == nil {
+		err = jobs.NotifyUser(r.Context(), tx, ownerID, models.NotifyLeaveDecision, notify.TemplateLeaveDecision, map[string]interface{}{
+			"Name":      ownerName,
+			"Type":      leave.Type,
+			"StartDate": leave.StartDate.Format("02-01-2006"),
+			"EndDate":   leave.EndDate.Format("02-01-2006"),
+			"Approved":  leave.Status == models.LeaveStatusApproved,
+			"Reviewer":  reviewerName,
+		})
+	}
+	if err != nil {
+		log.Println("Error queueing leave notification:", err)
+		http.Error(w, "Failed to update leave request", http.StatusInternalServerError)
+		return
+	}
+
+	event := models.WebhookLeaveRejected
+	if leave.Status == models.LeaveStatusApproved {
+		event = models.WebhookLeaveApproved
+	}
+	if err := jobs.EnqueueWebhookEvent(r.Context(), tx, event, leave); err != nil {
+		log.Println("Error queueing leave webhook:", err)
+		http.Error(w, "Failed to update leave request", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Println("Error committing leave decision:", err)
+		http.Error(w, "Failed to update leave request", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, models.AuditLeaveDecided, "leave_request", leaveID,
+		map[string]string{"status": currentStatus}, map[string]string{"status": data.Status})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(leave)
+}
@@ -0,0 +1,157 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"absensi/database"
+	"absensi/events"
+)
+
+// publishAttendanceEvent melengkapi event dengan nama, department & site user lalu mengirimkannya ke event bus.
+// Kegagalan hanya dicatat di log karena kehadiran sudah tersimpan.
+func publishAttendanceEvent(ctx context.Context, eventType, userID string, latitude, longitude float64) {
+	e := events.Event{
+		Type:      eventType,
+		UserID:    userID,
+		Latitude:  latitude,
+		Longitude: longitude,
+	}
+
+	var departmentID *string
+	err := database.DB.QueryRow(ctx, "SELECT name, department_id::TEXT, COALESCE(site, '') FROM users WHERE id = $1", userID).
+		Scan(&e.UserName, &departmentID, &e.Site)
+	if err != nil {
+		log.Println("Error fetching user for event:", err)
+	}
+	if departmentID != nil {
+		e.DepartmentID = *departmentID
+	}
+
+	events.Default.Publish(e)
+}
+
+// StreamAttendanceEvents mengirim event check-in/check-out/istirahat secara real time lewat Server-Sent Events.
+// Admin menerima event seluruh perusahaan, user lain hanya event bawahannya di site miliknya sendiri.
+// Scope bisa dipersempit dengan ?site= dan ?department_id=; selain admin hanya boleh memilih site sendiri.
+// Client yang reconnect mengirim header Last-Event-ID untuk menerima event yang terlewat.
+// EventSource di browser mengirim token lewat ?access_token= (lihat middleware.StreamAuthMiddleware).
+func StreamAttendanceEvents(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok || userID == "" {
+		http.Error(w, "User ID is missing", http.StatusUnauthorized)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	all, reportIDs, err := attendanceScope(r.Context(), userID)
+	if err != nil {
+		log.Println("Error resolving attendance scope:", err)
+		http.Error(w, "Failed to resolve attendance scope", http.StatusInternalServerError)
+		return
+	}
+	allowed := make(map[string]bool, len(reportIDs))
+	for _, id := range reportIDs {
+		allowed[id] = true
+	}
+	departmentID := r.URL.Query().Get("department_id")
+	site := r.URL.Query().Get("site")
+
+	// Selain admin dibatasi ke site miliknya; user tanpa site tidak dibatasi site
+	if !all {
+		var ownSite string
+		err := database.DB.QueryRow(r.Context(), "SELECT COALESCE(site, '') FROM users WHERE id = $1", userID).Scan(&ownSite)
+		if err != nil {
+			log.Println("Error fetching user site:", err)
+			http.Error(w, "Failed to resolve attendance scope", http.StatusInternalServerError)
+			return
+		}
+		if site != "" && site != ownSite {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		site = ownSite
+	}
+
+	visible := func(e events.Event) bool {
+		if departmentID != "" && e.DepartmentID != departmentID {
+			return false
+		}
+		if site != "" && e.Site != site {
+			return false
+		}
+		return all || allowed[e.UserID]
+	}
+
+	// Last-Event-ID dari header (reconnect otomatis EventSource) atau query string
+	lastIDStr := r.Header.Get("Last-Event-ID")
+	if lastIDStr == "" {
+		lastIDStr = r.URL.Query().Get("last_event_id")
+	}
+	lastID, _ := strconv.ParseInt(lastIDStr, 10, 64)
+
+	replay, ch, cancel := events.Default.Subscribe(lastID)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "retry: 5000\n\n")
+	flusher.Flush()
+
+	writeEvent := func(e events.Event) error {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+		return err
+	}
+
+	for _, e := range replay {
+		if visible(e) {
+			if err := writeEvent(e); err != nil {
+				return
+			}
+		}
+	}
+	flusher.Flush()
+
+	// Komentar heartbeat agar koneksi tidak diputus proxy
+	heartbeat := time.NewTicker(25 * time.Second)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case e, ok := <-ch:
+			if !ok {
+				return
+			}
+			if !visible(e) {
+				continue
+			}
+			if err := writeEvent(e); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
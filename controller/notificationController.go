@@ -0,0 +1,246 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"absensi/database"
+	"absensi/jobs"
+	"absensi/models"
+	"absensi/notify"
+	"absensi/utils"
+
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+)
+
+// queueAttendanceNotification mengantrikan tanda terima check-in/check-out dalam transaksi kehadiran
+// sesuai preferensi notifikasi user. Isi pesan memuat waktu, site dan keterlambatan (check-in)
+// atau jam kerja hari ini (check-out).
+func queueAttendanceNotification(ctx context.Context, tx pgx.Tx, userID, logType string) error {
+	var name, site string
+	err := tx.QueryRow(ctx, "SELECT name, COALESCE(site, '') FROM users WHERE id = $1", userID).Scan(&name, &site)
+	if err != nil {
+		return fmt.Errorf("fetch user: %w", err)
+	}
+
+	now := time.Now()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	var firstCheckIn *time.Time
+	err = tx.QueryRow(ctx, `
+        SELECT MIN(al.created_at)
+        FROM attendance_logs al JOIN attendance a ON al.attendance_id = a.id
+        WHERE a.user_id = $1 AND al.type = $2 AND al.created_at >= $3`, userID, models.LogTypeCheckIn, dayStart).Scan(&firstCheckIn)
+	if err != nil {
+		return fmt.Errorf("fetch first check-in: %w", err)
+	}
+
+	data := map[string]interface{}{
+		"Name": name,
+		"Date": now.Format("02-01-2006"),
+		"Time": now.Format("15:04"),
+		"Site": site,
+	}
+
+	key := notify.TemplateCheckIn
+	switch logType {
+	case models.LogTypeCheckIn:
+		// Keterlambatan dihitung dari check-in pertama hari ini
+		first := now
+		if firstCheckIn != nil {
+			first = firstCheckIn.In(now.Location())
+		}
+		lateMinutes := utils.GetSchedule().LateMinutes(first)
+		data["Late"] = lateMinutes > 0
+		data["LateMinutes"] = lateMinutes
+	case models.LogTypeCheckOut:
+		key = notify.TemplateCheckOut
+		data["WorkedHours"] = ""
+		if firstCheckIn != nil {
+			data["WorkedHours"] = fmt.Sprintf("%.1f", now.Sub(*firstCheckIn).Hours())
+		}
+	}
+
+	return jobs.NotifyUser(ctx, tx, userID, models.NotifyCheckInReceipt, key, data)
+}
+
+// validTemplateParams memeriksa key dan bahasa template dari URL
+func validTemplateParams(key, lang string) bool {
+	if _, ok := notify.DefaultTemplate(key, notify.LangID); !ok {
+		return false
+	}
+	return notify.NormalizeLanguage(lang) == lang
+}
+
+// GetNotificationTemplates mengembalikan template yang berlaku untuk setiap key dan bahasa
+func GetNotificationTemplates(w http.ResponseWriter, r *http.Request) {
+	rows, err := database.DB.Query(r.Context(), `
+        SELECT key, language, subject, text_body, COALESCE(html_body, ''), updated_at FROM notification_templates`)
+	if err != nil {
+		log.Println("Error fetching notification templates:", err)
+		http.Error(w, "Failed to fetch templates", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	custom := map[string]models.NotificationTemplate{}
+	for rows.Next() {
+		var t models.NotificationTemplate
+		if err := rows.Scan(&t.Key, &t.Language, &t.Subject, &t.Text, &t.HTML, &t.UpdatedAt); err != nil {
+			log.Println("Error scanning notification template:", err)
+			http.Error(w, "Error scanning data", http.StatusInternalServerError)
+			return
+		}
+		t.Customized = true
+		custom[t.Key+"/"+t.Language] = t
+	}
+
+	templates := []models.NotificationTemplate{}
+	for _, key := range notify.TemplateKeys() {
+		for _, lang := range notify.Languages {
+			if t, ok := custom[key+"/"+lang]; ok {
+				templates = append(templates, t)
+				continue
+			}
+			d, _ := notify.DefaultTemplate(key, lang)
+			templates = append(templates, models.NotificationTemplate{Key: key, Language: lang, Subject: d.Subject, Text: d.Text, HTML: d.HTML})
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(templates)
+}
+
+// UpdateNotificationTemplate menyimpan template buatan admin untuk satu key dan bahasa.
+// Template harus bisa dirender dengan data contoh sebelum disimpan.
+func UpdateNotificationTemplate(w http.ResponseWriter, r *http.Request) {
+	key, lang := mux.Vars(r)["key"], mux.Vars(r)["lang"]
+	if !validTemplateParams(key, lang) {
+		http.Error(w, "Unknown template or language", http.StatusNotFound)
+		return
+	}
+
+	var data struct {
+		Subject string `json:"subject"`
+		Text    string `json:"text"`
+		HTML    string `json:"html"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(data.Subject) == "" || strings.TrimSpace(data.Text) == "" {
+		http.Error(w, "Subject and text are required", http.StatusBadRequest)
+		return
+	}
+
+	tmpl := notify.Template{Key: key, Language: lang, Subject: data.Subject, Text: data.Text, HTML: data.HTML}
+	if _, err := tmpl.Render("preview@example.com", notify.SampleData(key)); err != nil {
+		http.Error(w, "Invalid template: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	before, err := jobs.LoadTemplate(r.Context(), database.DB, key, lang)
+	if err != nil {
+		log.Println("Error loading notification template:", err)
+		http.Error(w, "Failed to load template", http.StatusInternalServerError)
+		return
+	}
+
+	query := `
+        INSERT INTO notification_templates (key, language, subject, text_body, html_body, updated_by, updated_at)
+        VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, '')::UUID, NOW())
+        ON CONFLICT (key, language) DO UPDATE SET
+            subject = EXCLUDED.subject, text_body = EXCLUDED.text_body, html_body = EXCLUDED.html_body,
+            updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at
+        RETURNING updated_at`
+	adminID, _ := r.Context().Value("user_id").(string)
+	saved := models.NotificationTemplate{Key: key, Language: lang, Subject: data.Subject, Text: data.Text, HTML: data.HTML, Customized: true}
+	err = database.DB.QueryRow(r.Context(), query, key, lang, data.Subject, data.Text, data.HTML, adminID).Scan(&saved.UpdatedAt)
+	if err != nil {
+		log.Println("Error saving notification template:", err)
+		http.Error(w, "Failed to save template", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, models.AuditTemplateUpdated, "notification_template", key+"/"+lang, before, tmpl)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(saved)
+}
+
+// ResetNotificationTemplate menghapus template buatan admin sehingga template bawaan dipakai lagi
+func ResetNotificationTemplate(w http.ResponseWriter, r *http.Request) {
+	key, lang := mux.Vars(r)["key"], mux.Vars(r)["lang"]
+	if !validTemplateParams(key, lang) {
+		http.Error(w, "Unknown template or language", http.StatusNotFound)
+		return
+	}
+
+	tag, err := database.DB.Exec(r.Context(), "DELETE FROM notification_templates WHERE key = $1 AND language = $2", key, lang)
+	if err != nil {
+		log.Println("Error resetting notification template:", err)
+		http.Error(w, "Failed to reset template", http.StatusInternalServerError)
+		return
+	}
+	if tag.RowsAffected() > 0 {
+		recordAudit(r, models.AuditTemplateReset, "notification_template", key+"/"+lang, nil, nil)
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Template reset to default"})
+}
+
+// PreviewNotificationTemplate merender template dengan data contoh. Body boleh berisi draft
+// subject/text/html yang belum disimpan dan data untuk menimpa data contoh.
+func PreviewNotificationTemplate(w http.ResponseWriter, r *http.Request) {
+	key, lang := mux.Vars(r)["key"], mux.Vars(r)["lang"]
+	if !validTemplateParams(key, lang) {
+		http.Error(w, "Unknown template or language", http.StatusNotFound)
+		return
+	}
+
+	var draft struct {
+		Subject string                 `json:"subject"`
+		Text    string                 `json:"text"`
+		HTML    string                 `json:"html"`
+		Data    map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&draft); err != nil && err != io.EOF {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	tmpl, err := jobs.LoadTemplate(r.Context(), database.DB, key, lang)
+	if err != nil {
+		log.Println("Error loading notification template:", err)
+		http.Error(w, "Failed to load template", http.StatusInternalServerError)
+		return
+	}
+	if draft.Subject != "" || draft.Text != "" || draft.HTML != "" {
+		tmpl.Subject, tmpl.Text, tmpl.HTML = draft.Subject, draft.Text, draft.HTML
+	}
+
+	sample := map[string]interface{}{}
+	for k, v := range notify.SampleData(key) {
+		sample[k] = v
+	}
+	for k, v := range draft.Data {
+		sample[k] = v
+	}
+
+	msg, err := tmpl.Render("preview@example.com", sample)
+	if err != nil {
+		http.Error(w, "Invalid template: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(msg)
+}
@@ -0,0 +1,506 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	"absensi/database"
+	"absensi/models"
+
+	"github.com/gorilla/mux"
+)
+
+// getUserRole mengambil role user dari database
+func getUserRole(ctx context.Context, userID string) (string, error) {
+	var role string
+	err := database.DB.QueryRow(ctx, "SELECT role FROM users WHERE id = $1", userID).Scan(&role)
+	return role, err
+}
+
+// getReportIDs mengembalikan semua bawahan (langsung maupun tidak langsung) dari seorang manager
+func getReportIDs(ctx context.Context, managerID string) ([]string, error) {
+	query := `
+        WITH RECURSIVE reports AS (
+            SELECT id FROM users WHERE manager_id = $1
+            UNION
+            SELECT u.id FROM users u JOIN reports r ON u.manager_id = r.id
+        )
+        SELECT id::TEXT FROM reports`
+
+	rows, err := database.DB.Query(ctx, query, managerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// getActiveReportIDs mengembalikan bawahan (rekursif) seorang manager yang belum dihapus maupun dinonaktifkan.
+// getReportIDs tetap menyertakan mereka agar riwayat kehadirannya masih bisa dilihat atasannya.
+func getActiveReportIDs(ctx context.Context, managerID string) ([]string, error) {
+	reportIDs, err := getReportIDs(ctx, managerID)
+	if err != nil || len(reportIDs) == 0 {
+		return reportIDs, err
+	}
+
+	rows, err := database.DB.Query(ctx, `
+        SELECT id::TEXT FROM users
+        WHERE id::TEXT = ANY($1) AND deleted_at IS NULL AND COALESCE(status, 'active') = 'active'`, reportIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// attendanceScope menentukan data kehadiran siapa saja yang boleh dilihat user.
+// Admin melihat semua user, selain itu hanya bawahan (rekursif) dari user tersebut.
+func attendanceScope(ctx context.Context, userID string) (all bool, userIDs []string, err error) {
+	role, err := getUserRole(ctx, userID)
+	if err != nil {
+		return false, nil, err
+	}
+	if role == models.RoleAdmin {
+		return true, nil, nil
+	}
+
+	userIDs, err = getReportIDs(ctx, userID)
+	return false, userIDs, err
+}
+
+// isInScope memastikan viewer boleh melihat/menyetujui data milik targetID
+func isInScope(ctx context.Context, viewerID, targetID string) (bool, error) {
+	all, reportIDs, err := attendanceScope(ctx, viewerID)
+	if err != nil || all {
+		return all, err
+	}
+	for _, id := range reportIDs {
+		if id == targetID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func GetDepartments(w http.ResponseWriter, r *http.Request) {
+	rows, err := database.DB.Query(r.Context(), "SELECT id::TEXT, name, created_at FROM departments ORDER BY name ASC")
+	if err != nil {
+		log.Println("Error fetching departments:", err)
+		http.Error(w, "Failed to fetch departments", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	departments := []models.Department{}
+	for rows.Next() {
+		var dept models.Department
+		if err := rows.Scan(&dept.ID, &dept.Name, &dept.CreatedAt); err != nil {
+			log.Println("Error scanning department:", err)
+			http.Error(w, "Error scanning data", http.StatusInternalServerError)
+			return
+		}
+		departments = append(departments, dept)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(departments)
+}
+
+func CreateDepartment(w http.ResponseWriter, r *http.Request) {
+	var dept models.Department
+	if err := json.NewDecoder(r.Body).Decode(&dept); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	dept.Name = strings.TrimSpace(dept.Name)
+	if dept.Name == "" {
+		http.Error(w, "Department name is required", http.StatusBadRequest)
+		return
+	}
+
+	query := `INSERT INTO departments (name, created_at) VALUES ($1, NOW()) RETURNING id::TEXT, created_at`
+	err := database.DB.QueryRow(r.Context(), query, dept.Name).Scan(&dept.ID, &dept.CreatedAt)
+	if err != nil {
+		log.Println("Error creating department:", err)
+		http.Error(w, "Failed to create department", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, models.AuditDepartmentCreated, "department", dept.ID, nil, dept)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(dept)
+}
+
+func UpdateDepartment(w http.ResponseWriter, r *http.Request) {
+	deptID := mux.Vars(r)["id"]
+
+	var data struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	data.Name = strings.TrimSpace(data.Name)
+	if data.Name == "" {
+		http.Error(w, "Department name is required", http.StatusBadRequest)
+		return
+	}
+
+	var oldName string
+	if err := database.DB.QueryRow(r.Context(), "SELECT name FROM departments WHERE id = $1", deptID).Scan(&oldName); err != nil {
+		http.Error(w, "Department not found", http.StatusNotFound)
+		return
+	}
+
+	tag, err := database.DB.Exec(r.Context(), "UPDATE departments SET name = $1 WHERE id = $2", data.Name, deptID)
+	if err != nil {
+		log.Println("Error updating department:", err)
+		http.Error(w, "Failed to update department", http.StatusInternalServerError)
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		http.Error(w, "Department not found", http.StatusNotFound)
+		return
+	}
+
+	recordAudit(r, models.AuditDepartmentUpdated, "department", deptID, map[string]string{"name": oldName}, map[string]string{"name": data.Name})
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Department updated"})
+}
+
+func DeleteDepartment(w http.ResponseWriter, r *http.Request) {
+	deptID := mux.Vars(r)["id"]
+
+	tag, err := database.DB.Exec(r.Context(), "DELETE FROM departments WHERE id = $1", deptID)
+	if err != nil {
+		log.Println("Error deleting department:", err)
+		http.Error(w, "Failed to delete department", http.StatusInternalServerError)
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		http.Error(w, "Department not found", http.StatusNotFound)
+		return
+	}
+
+	recordAudit(r, models.AuditDepartmentDeleted, "department", deptID, nil, nil)
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Department deleted"})
+}
+
+func GetTeams(w http.ResponseWriter, r *http.Request) {
+	query := "SELECT id::TEXT, department_id::TEXT, name, created_at FROM teams ORDER BY name ASC"
+	args := []interface{}{}
+
+	// Filter opsional berdasarkan department
+	if deptID := r.URL.Query().Get("department_id"); deptID != "" {
+		query = "SELECT id::TEXT, department_id::TEXT, name, created_at FROM teams WHERE department_id = $1 ORDER BY name ASC"
+		args = append(args, deptID)
+	}
+
+	rows, err := database.DB.Query(r.Context(), query, args...)
+	if err != nil {
+		log.Println("Error fetching teams:", err)
+		http.Error(w, "Failed to fetch teams", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	teams := []models.Team{}
+	for rows.Next() {
+		var team models.Team
+		if err := rows.Scan(&team.ID, &team.DepartmentID, &team.Name, &team.CreatedAt); err != nil {
+			log.Println("Error scanning team:", err)
+			http.Error(w, "Error scanning data", http.StatusInternalServerError)
+			return
+		}
+		teams = append(teams, team)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(teams)
+}
+
+func CreateTeam(w http.ResponseWriter, r *http.Request) {
+	var team models.Team
+	if err := json.NewDecoder(r.Body).Decode(&team); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	team.Name = strings.TrimSpace(team.Name)
+	if team.Name == "" || team.DepartmentID == "" {
+		http.Error(w, "Team name and department_id are required", http.StatusBadRequest)
+		return
+	}
+
+	query := `INSERT INTO teams (department_id, name, created_at) VALUES ($1, $2, NOW()) RETURNING id::TEXT, created_at`
+	err := database.DB.QueryRow(r.Context(), query, team.DepartmentID, team.Name).Scan(&team.ID, &team.CreatedAt)
+	if err != nil {
+		log.Println("Error creating team:", err)
+		http.Error(w, "Failed to create team", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, models.AuditTeamCreated, "team", team.ID, nil, team)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(team)
+}
+
+func UpdateTeam(w http.ResponseWriter, r *http.Request) {
+	teamID := mux.Vars(r)["id"]
+
+	var data struct {
+		Name         string `json:"name"`
+		DepartmentID string `json:"department_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	data.Name = strings.TrimSpace(data.Name)
+	if data.Name == "" || data.DepartmentID == "" {
+		http.Error(w, "Team name and department_id are required", http.StatusBadRequest)
+		return
+	}
+
+	var before models.Team
+	err := database.DB.QueryRow(r.Context(), "SELECT id::TEXT, department_id::TEXT, name, created_at FROM teams WHERE id = $1", teamID).
+		Scan(&before.ID, &before.DepartmentID, &before.Name, &before.CreatedAt)
+	if err != nil {
+		http.Error(w, "Team not found", http.StatusNotFound)
+		return
+	}
+
+	tag, err := database.DB.Exec(r.Context(), "UPDATE teams SET name = $1, department_id = $2 WHERE id = $3", data.Name, data.DepartmentID, teamID)
+	if err != nil {
+		log.Println("Error updating team:", err)
+		http.Error(w, "Failed to update team", http.StatusInternalServerError)
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		http.Error(w, "Team not found", http.StatusNotFound)
+		return
+	}
+
+	recordAudit(r, models.AuditTeamUpdated, "team", teamID,
+		map[string]string{"name": before.Name, "department_id": before.DepartmentID},
+		map[string]string{"name": data.Name, "department_id": data.DepartmentID})
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Team updated"})
+}
+
+func DeleteTeam(w http.ResponseWriter, r *http.Request) {
+	teamID := mux.Vars(r)["id"]
+
+	tag, err := database.DB.Exec(r.Context(), "DELETE FROM teams WHERE id = $1", teamID)
+	if err != nil {
+		log.Println("Error deleting team:", err)
+		http.Error(w, "Failed to delete team", http.StatusInternalServerError)
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		http.Error(w, "Team not found", http.StatusNotFound)
+		return
+	}
+
+	recordAudit(r, models.AuditTeamDeleted, "team", teamID, nil, nil)
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Team deleted"})
+}
+
+// UpdateUserOrg mengatur department, team dan manager dari seorang user
+func UpdateUserOrg(w http.ResponseWriter, r *http.Request) {
+	userID := mux.Vars(r)["id"]
+
+	var data struct {
+		DepartmentID *string `json:"department_id"`
+		TeamID       *string `json:"team_id"`
+		ManagerID    *string `json:"manager_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	// String kosong berarti menghapus relasi
+	for _, field := range []**string{&data.DepartmentID, &data.TeamID, &data.ManagerID} {
+		if *field != nil && **field == "" {
+			*field = nil
+		}
+	}
+
+	// Cegah siklus: manager tidak boleh user itu sendiri atau salah satu bawahannya
+	if data.ManagerID != nil {
+		if *data.ManagerID == userID {
+			http.Error(w, "User cannot be their own manager", http.StatusBadRequest)
+			return
+		}
+		reports, err := getReportIDs(r.Context(), userID)
+		if err != nil {
+			log.Println("Error fetching reports:", err)
+			http.Error(w, "Failed to validate manager", http.StatusInternalServerError)
+			return
+		}
+		for _, id := range reports {
+			if id == *data.ManagerID {
+				http.Error(w, "Manager cannot be one of the user's reports", http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
+	// Data lama untuk audit log
+	before := map[string]*string{}
+	var oldDept, oldTeam, oldManager *string
+	err := database.DB.QueryRow(r.Context(), "SELECT department_id::TEXT, team_id::TEXT, manager_id::TEXT FROM users WHERE id = $1", userID).
+		Scan(&oldDept, &oldTeam, &oldManager)
+	if err != nil {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+	before["department_id"], before["team_id"], before["manager_id"] = oldDept, oldTeam, oldManager
+
+	query := "UPDATE users SET department_id = $1, team_id = $2, manager_id = $3 WHERE id = $4"
+	tag, err := database.DB.Exec(r.Context(), query, data.DepartmentID, data.TeamID, data.ManagerID, userID)
+	if err != nil {
+		log.Println("Error updating user org:", err)
+		http.Error(w, "Failed to update user organization", http.StatusInternalServerError)
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	recordAudit(r, models.AuditUserOrgChanged, "user", userID, before,
+		map[string]*string{"department_id": data.DepartmentID, "team_id": data.TeamID, "manager_id": data.ManagerID})
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "User organization updated"})
+}
+
+// GetTeamReports mengembalikan daftar bawahan dari user yang login
+func GetTeamReports(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok || userID == "" {
+		http.Error(w, "User ID is missing", http.StatusUnauthorized)
+		return
+	}
+
+	query := `
+        SELECT id::TEXT, name, email, role, department_id::TEXT, team_id::TEXT, manager_id::TEXT, created_at
+        FROM users WHERE manager_id = $1 AND deleted_at IS NULL ORDER BY name ASC`
+	args := []interface{}{userID}
+
+	// Secara default semua bawahan (rekursif), ?direct=true untuk bawahan langsung saja
+	if r.URL.Query().Get("direct") != "true" {
+		reportIDs, err := getReportIDs(r.Context(), userID)
+		if err != nil {
+			log.Println("Error fetching reports:", err)
+			http.Error(w, "Failed to fetch reports", http.StatusInternalServerError)
+			return
+		}
+		query = `
+            SELECT id::TEXT, name, email, role, department_id::TEXT, team_id::TEXT, manager_id::TEXT, created_at
+            FROM users WHERE id::TEXT = ANY($1) AND deleted_at IS NULL ORDER BY name ASC`
+		args = []interface{}{reportIDs}
+	}
+
+	rows, err := database.DB.Query(r.Context(), query, args...)
+	if err != nil {
+		log.Println("Error fetching reports:", err)
+		http.Error(w, "Failed to fetch reports", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		var user models.User
+		err := rows.Scan(&user.ID, &user.Name, &user.Email, &user.Role, &user.DepartmentID, &user.TeamID, &user.ManagerID, &user.CreatedAt)
+		if err != nil {
+			log.Println("Error scanning user:", err)
+			http.Error(w, "Error scanning data", http.StatusInternalServerError)
+			return
+		}
+		users = append(users, user)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(users)
+}
+
+// GetAttendanceByDepartment mengelompokkan rekap kehadiran harian satu bulan per department.
+// ?department_id= untuk satu department saja. User tanpa department dikelompokkan paling akhir.
+func GetAttendanceByDepartment(w http.ResponseWriter, r *http.Request) {
+	month, year, err := parseMonthYear(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	recaps, err := buildMonthlyRecaps(r.Context(), month, year, true, nil, r.URL.Query().Get("department_id"))
+	if err != nil {
+		log.Println("Error fetching department attendance:", err)
+		http.Error(w, "Failed to fetch data", http.StatusInternalServerError)
+		return
+	}
+
+	groups := []models.DepartmentAttendance{}
+	index := map[string]int{}
+	for _, recap := range recaps {
+		i, ok := index[recap.DepartmentID]
+		if !ok {
+			name := recap.DepartmentName
+			if recap.DepartmentID == "" {
+				name = "Tanpa Departemen"
+			}
+			i = len(groups)
+			index[recap.DepartmentID] = i
+			groups = append(groups, models.DepartmentAttendance{
+				DepartmentID:   recap.DepartmentID,
+				DepartmentName: name,
+				Attendances:    []models.DailyAttendance{},
+			})
+		}
+		groups[i].Attendances = append(groups[i].Attendances, recap.Days...)
+	}
+	sort.SliceStable(groups, func(a, b int) bool {
+		if (groups[a].DepartmentID == "") != (groups[b].DepartmentID == "") {
+			return groups[b].DepartmentID == ""
+		}
+		return groups[a].DepartmentName < groups[b].DepartmentName
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(groups)
+}
@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"absensi/database"
+	"absensi/models"
+
+	"github.com/gorilla/mux"
+)
+
+const outboxColumns = `id::TEXT, channel, recipient, subject, body, COALESCE(html_body, ''), status, attempts, max_attempts,
+        next_attempt_at, COALESCE(last_error, ''), created_at, sent_at`
+
+func scanOutboxMessage(row rowScanner) (models.OutboxMessage, error) {
+	var msg models.OutboxMessage
+	err := row.Scan(&msg.ID, &msg.Channel, &msg.Recipient, &msg.Subject, &msg.Body, &msg.HTML, &msg.Status, &msg.Attempts,
+		&msg.MaxAttempts, &msg.NextAttemptAt, &msg.LastError, &msg.CreatedAt, &msg.SentAt)
+	return msg, err
+}
+
+// GetOutboxMessages mengembalikan isi outbox notifikasi, bisa difilter dengan ?status= dan ?recipient=
+func GetOutboxMessages(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	recipient := r.URL.Query().Get("recipient")
+
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	pageSize, err := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if err != nil || pageSize < 1 || pageSize > 200 {
+		pageSize = 50
+	}
+
+	where := `WHERE ($1 = '' OR status = $1) AND ($2 = '' OR recipient = $2)`
+	result := models.OutboxPage{Data: []models.OutboxMessage{}, Page: page, PageSize: pageSize}
+	if err := database.DB.QueryRow(r.Context(), "SELECT COUNT(*) FROM notification_outbox "+where, status, recipient).Scan(&result.Total); err != nil {
+		log.Println("Error counting outbox messages:", err)
+		http.Error(w, "Failed to fetch outbox", http.StatusInternalServerError)
+		return
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM notification_outbox %s ORDER BY created_at DESC LIMIT %d OFFSET %d",
+		outboxColumns, where, pageSize, (page-1)*pageSize)
+	rows, err := database.DB.Query(r.Context(), query, status, recipient)
+	if err != nil {
+		log.Println("Error fetching outbox messages:", err)
+		http.Error(w, "Failed to fetch outbox", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		msg, err := scanOutboxMessage(rows)
+		if err != nil {
+			log.Println("Error scanning outbox message:", err)
+			http.Error(w, "Error scanning data", http.StatusInternalServerError)
+			return
+		}
+		result.Data = append(result.Data, msg)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// RetryOutboxMessage mengantrikan ulang satu pesan yang gagal (dead) untuk segera dikirim
+func RetryOutboxMessage(w http.ResponseWriter, r *http.Request) {
+	messageID := mux.Vars(r)["id"]
+
+	query := `
+        UPDATE notification_outbox SET status = $1, attempts = 0, next_attempt_at = NOW()
+        WHERE id = $2 AND status = $3
+        RETURNING ` + outboxColumns
+	msg, err := scanOutboxMessage(database.DB.QueryRow(r.Context(), query, models.OutboxStatusPending, messageID, models.OutboxStatusDead))
+	if err != nil {
+		http.Error(w, "Dead outbox message not found", http.StatusNotFound)
+		return
+	}
+
+	recordAudit(r, models.AuditOutboxRedriven, "outbox_message", messageID, nil, nil)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(msg)
+}
+
+// RetryDeadOutboxMessages mengantrikan ulang semua pesan dead sekaligus
+func RetryDeadOutboxMessages(w http.ResponseWriter, r *http.Request) {
+	tag, err := database.DB.Exec(r.Context(), `
+        UPDATE notification_outbox SET status = $1, attempts = 0, next_attempt_at = NOW()
+        WHERE status = $2`, models.OutboxStatusPending, models.OutboxStatusDead)
+	if err != nil {
+		log.Println("Error re-driving outbox messages:", err)
+		http.Error(w, "Failed to retry messages", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, models.AuditOutboxRedriven, "outbox_message", "", nil, map[string]int64{"requeued": tag.RowsAffected()})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int64{"requeued": tag.RowsAffected()})
+}
@@ -0,0 +1,154 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"absensi/models"
+	"absensi/utils"
+)
+
+// parsePayrollPeriod membaca periode dari ?from=&to= (YYYY-MM-DD, inklusif) atau ?month=&year=
+func parsePayrollPeriod(r *http.Request) (time.Time, time.Time, error) {
+	fromStr, toStr := r.URL.Query().Get("from"), r.URL.Query().Get("to")
+	if fromStr == "" && toStr == "" {
+		month, year, err := parseMonthYear(r)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		from := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.Local)
+		return from, from.AddDate(0, 1, 0), nil
+	}
+
+	from, err := time.ParseInLocation("2006-01-02", fromStr, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("Invalid from, expected YYYY-MM-DD")
+	}
+	to, err := time.ParseInLocation("2006-01-02", toStr, time.Local)
+	if err != nil || to.Before(from) {
+		return time.Time{}, time.Time{}, errors.New("Invalid to, expected YYYY-MM-DD not before from")
+	}
+	if to.Sub(from) > 62*24*time.Hour {
+		return time.Time{}, time.Time{}, errors.New("Payroll period cannot exceed 62 days")
+	}
+	return from, to.AddDate(0, 0, 1), nil
+}
+
+// payrollEntry menghitung ringkasan payroll dari rekap harian satu karyawan
+func payrollEntry(recap models.MonthlyRecap, rules utils.PayrollRules) models.PayrollEntry {
+	entry := models.PayrollEntry{
+		UserID:         recap.UserID,
+		Name:           recap.Name,
+		DepartmentName: recap.DepartmentName,
+	}
+
+	overtimePay := 0.0
+	for _, day := range recap.Days {
+		if day.Status == models.DailyStatusNotEmployed {
+			continue
+		}
+		restDay := day.Date.Weekday() == time.Saturday || day.Date.Weekday() == time.Sunday
+		if !restDay {
+			entry.WorkingDays++
+		}
+
+		switch day.Status {
+		case models.DailyStatusPresent, models.DailyStatusLate, models.DailyStatusNoCheckOut:
+			entry.DaysPresent++
+			if day.LateMinutes > 0 && !restDay {
+				entry.LateDays++
+				entry.LateMinutes += day.LateMinutes
+				entry.DeductibleLateMinutes += rules.DeductibleLateMinutes(day.LateMinutes)
+			}
+
+			// Semua jam kerja di hari istirahat dihitung lembur hari istirahat
+			if restDay {
+				entry.RestDayOvertimeHours += day.WorkedHours
+				overtimePay += utils.WeightedOvertime(day.WorkedHours, rules.RestDayOvertime)
+			} else {
+				entry.WeekdayOvertimeHours += day.OvertimeHours
+				overtimePay += utils.WeightedOvertime(day.OvertimeHours, rules.WeekdayOvertime)
+			}
+		case models.DailyStatusAbsent:
+			entry.UnpaidAbsences++
+		case models.DailyStatusLeave:
+			if restDay {
+				continue
+			}
+			if rules.IsPaidLeave(day.LeaveType) {
+				entry.PaidLeave++
+			} else {
+				entry.UnpaidLeave++
+			}
+		}
+	}
+
+	entry.WeekdayOvertimeHours = math.Round(entry.WeekdayOvertimeHours*100) / 100
+	entry.RestDayOvertimeHours = math.Round(entry.RestDayOvertimeHours*100) / 100
+	entry.OvertimePayHours = math.Round(overtimePay*100) / 100
+	return entry
+}
+
+// GetPayrollExport menghitung data payroll semua karyawan untuk satu periode, dalam format JSON atau CSV
+func GetPayrollExport(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parsePayrollPeriod(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rules, err := utils.LoadPayrollRules()
+	if err != nil {
+		log.Println("Error loading payroll rules:", err)
+		http.Error(w, "Failed to load payroll rules", http.StatusInternalServerError)
+		return
+	}
+
+	recaps, err := buildRecaps(r.Context(), from, to, true, nil, r.URL.Query().Get("department_id"))
+	if err != nil {
+		log.Println("Error building payroll recap:", err)
+		http.Error(w, "Failed to build payroll data", http.StatusInternalServerError)
+		return
+	}
+
+	export := models.PayrollExport{
+		From:    from.Format("2006-01-02"),
+		To:      to.AddDate(0, 0, -1).Format("2006-01-02"),
+		Entries: make([]models.PayrollEntry, 0, len(recaps)),
+	}
+	for _, recap := range recaps {
+		export.Entries = append(export.Entries, payrollEntry(recap, rules))
+	}
+
+	if exportFormat(r) != formatCSV {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(export)
+		return
+	}
+
+	filename := fmt.Sprintf("payroll-%s-%s.csv", export.From, export.To)
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+	table := utils.NewCSVWriter(w)
+	table.WriteRow("user_id", "name", "department", "working_days", "days_present", "late_days", "late_minutes",
+		"deductible_late_minutes", "unpaid_absences", "paid_leave", "unpaid_leave",
+		"weekday_overtime_hours", "rest_day_overtime_hours", "overtime_pay_hours")
+	for _, e := range export.Entries {
+		err := table.WriteRow(e.UserID, e.Name, e.DepartmentName, e.WorkingDays, e.DaysPresent, e.LateDays, e.LateMinutes,
+			e.DeductibleLateMinutes, e.UnpaidAbsences, e.PaidLeave, e.UnpaidLeave,
+			e.WeekdayOvertimeHours, e.RestDayOvertimeHours, e.OvertimePayHours)
+		if err != nil {
+			log.Println("Error writing payroll CSV:", err)
+			return
+		}
+	}
+	if err := table.Close(); err != nil {
+		log.Println("Error finishing payroll CSV:", err)
+	}
+}
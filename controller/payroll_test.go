@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"absensi/models"
+	"absensi/utils"
+)
+
+func TestPayrollEntryMidPeriodHire(t *testing.T) {
+	from := time.Date(2026, 9, 1, 0, 0, 0, 0, time.Local)
+	to := from.AddDate(0, 1, 0)
+	today := time.Date(2026, 10, 19, 0, 0, 0, 0, time.Local)
+	checkIn := func(day int) *time.Time {
+		t := time.Date(2026, 9, day, 8, 0, 0, 0, time.Local)
+		return &t
+	}
+	present := func(day int) models.DailyAttendance {
+		out := checkIn(day).Add(9 * time.Hour)
+		return models.DailyAttendance{Date: time.Date(2026, 9, day, 0, 0, 0, 0, time.Local), CheckIn: checkIn(day), CheckOut: &out,
+			Status: models.DailyStatusPresent, WorkedHours: 9}
+	}
+
+	tests := []struct {
+		name       string
+		employed   employment
+		attended   map[string]models.DailyAttendance
+		wantWork   int
+		wantAbsent int
+		wantDays   int
+	}{
+		{
+			// Bergabung Senin 14 September siang: 1-11 September bukan absen
+			name:       "hired mid period",
+			employed:   employment{from: time.Date(2026, 9, 14, 13, 0, 0, 0, time.Local)},
+			attended:   map[string]models.DailyAttendance{"2026-09-15": present(15), "2026-09-16": present(16)},
+			wantWork:   13,
+			wantAbsent: 11,
+			wantDays:   2,
+		},
+		{
+			name:       "employed all period",
+			employed:   employment{from: time.Date(2025, 1, 1, 0, 0, 0, 0, time.Local)},
+			attended:   map[string]models.DailyAttendance{"2026-09-15": present(15)},
+			wantWork:   22,
+			wantAbsent: 21,
+			wantDays:   1,
+		},
+		{
+			// Dihapus Jumat 4 September sore: hari setelahnya tidak dihitung
+			name: "deleted mid period",
+			employed: employment{from: time.Date(2025, 1, 1, 0, 0, 0, 0, time.Local),
+				until: func() *time.Time { t := time.Date(2026, 9, 4, 17, 0, 0, 0, time.Local); return &t }()},
+			attended:   map[string]models.DailyAttendance{"2026-09-01": present(1)},
+			wantWork:   4,
+			wantAbsent: 3,
+			wantDays:   1,
+		},
+		{
+			name:       "attendance before hire date is ignored",
+			employed:   employment{from: time.Date(2026, 9, 28, 0, 0, 0, 0, time.Local)},
+			attended:   map[string]models.DailyAttendance{"2026-09-01": present(1), "2026-09-28": present(28)},
+			wantWork:   3,
+			wantAbsent: 2,
+			wantDays:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recap := models.MonthlyRecap{UserID: "u1", Name: "Budi Santoso"}
+			assembleRecap(&recap, from, to, today, tt.employed, tt.attended, nil)
+			if len(recap.Days) != 30 {
+				t.Fatalf("got %d days, want 30", len(recap.Days))
+			}
+
+			entry := payrollEntry(recap, utils.DefaultPayrollRules())
+			if entry.WorkingDays != tt.wantWork || entry.UnpaidAbsences != tt.wantAbsent || entry.DaysPresent != tt.wantDays {
+				t.Errorf("working days = %d, unpaid absences = %d, days present = %d; want %d, %d, %d",
+					entry.WorkingDays, entry.UnpaidAbsences, entry.DaysPresent, tt.wantWork, tt.wantAbsent, tt.wantDays)
+			}
+			if recap.Totals.Absent != tt.wantAbsent {
+				t.Errorf("recap absent = %d, want %d", recap.Totals.Absent, tt.wantAbsent)
+			}
+		})
+	}
+}
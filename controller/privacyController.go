@@ -0,0 +1,410 @@
+package controller
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"absensi/database"
+	"absensi/jobs"
+	"absensi/models"
+	"absensi/utils"
+
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+)
+
+const erasureColumns = `id::TEXT, user_id::TEXT, COALESCE(reason, ''), status, COALESCE(note, ''),
+        reviewed_by::TEXT, reviewed_at, scheduled_for, created_at`
+
+func scanErasureRequest(row rowScanner) (models.ErasureRequest, error) {
+	var req models.ErasureRequest
+	err := row.Scan(&req.ID, &req.UserID, &req.Reason, &req.Status, &req.Note, &req.ReviewedBy, &req.ReviewedAt, &req.ScheduledFor, &req.CreatedAt)
+	return req, err
+}
+
+// collectPersonalData mengumpulkan semua data yang tersimpan tentang satu user
+func collectPersonalData(ctx context.Context, userID string) (models.PersonalDataExport, error) {
+	data := models.PersonalDataExport{
+		GeneratedAt:         time.Now(),
+		Attendance:          []models.Attendance{},
+		AttendanceLogs:      []models.AttendanceLogExport{},
+		LeaveRequests:       []models.LeaveRequest{},
+		FingerprintMappings: []models.FingerprintMapping{},
+		AuditLogs:           []models.AuditLog{},
+		ErasureRequests:     []models.ErasureRequest{},
+	}
+
+	profile, err := getProfile(ctx, userID)
+	if err != nil {
+		return data, err
+	}
+	data.Profile = profile
+
+	rows, err := database.DB.Query(ctx, `
+        SELECT id::TEXT, user_id::TEXT, check_in, check_out, latitude, longitude, status
+        FROM attendance WHERE user_id = $1 ORDER BY check_in ASC`, userID)
+	if err != nil {
+		return data, fmt.Errorf("fetch attendance: %w", err)
+	}
+	for rows.Next() {
+		att, err := scanAttendance(rows)
+		if err != nil {
+			rows.Close()
+			return data, fmt.Errorf("scan attendance: %w", err)
+		}
+		data.Attendance = append(data.Attendance, att)
+	}
+	rows.Close()
+
+	rows, err = database.DB.Query(ctx, `
+        SELECT al.id::TEXT, al.attendance_id::TEXT, COALESCE(al.type, ''), COALESCE(al.source, 'app'),
+               COALESCE(al.location_name, ''), COALESCE(al.notes, ''), al.latitude, al.longitude, al.created_at
+        FROM attendance_logs al JOIN attendance a ON al.attendance_id = a.id
+        WHERE a.user_id = $1
+        ORDER BY al.created_at ASC`, userID)
+	if err != nil {
+		return data, fmt.Errorf("fetch attendance logs: %w", err)
+	}
+	for rows.Next() {
+		var entry models.AttendanceLogExport
+		err := rows.Scan(&entry.ID, &entry.AttendanceID, &entry.Type, &entry.Source, &entry.LocationName, &entry.Notes,
+			&entry.Latitude, &entry.Longitude, &entry.CreatedAt)
+		if err != nil {
+			rows.Close()
+			return data, fmt.Errorf("scan attendance log: %w", err)
+		}
+		data.AttendanceLogs = append(data.AttendanceLogs, entry)
+	}
+	rows.Close()
+
+	rows, err = database.DB.Query(ctx, `SELECT `+leaveColumns+` FROM leave_requests WHERE user_id = $1 ORDER BY start_date ASC`, userID)
+	if err != nil {
+		return data, fmt.Errorf("fetch leave requests: %w", err)
+	}
+	for rows.Next() {
+		leave, err := scanLeave(rows)
+		if err != nil {
+			rows.Close()
+			return data, fmt.Errorf("scan leave request: %w", err)
+		}
+		data.LeaveRequests = append(data.LeaveRequests, leave)
+	}
+	rows.Close()
+
+	rows, err = database.DB.Query(ctx, `
+        SELECT device_id, device_user_id, user_id::TEXT, created_at FROM fingerprint_mappings WHERE user_id = $1`, userID)
+	if err != nil {
+		return data, fmt.Errorf("fetch fingerprint mappings: %w", err)
+	}
+	for rows.Next() {
+		var m models.FingerprintMapping
+		if err := rows.Scan(&m.DeviceID, &m.DeviceUserID, &m.UserID, &m.CreatedAt); err != nil {
+			rows.Close()
+			return data, fmt.Errorf("scan fingerprint mapping: %w", err)
+		}
+		data.FingerprintMappings = append(data.FingerprintMappings, m)
+	}
+	rows.Close()
+
+	// Audit log tentang user ini atau yang dilakukan oleh user ini. Nilai before/after pada aksi
+	// yang dilakukan terhadap orang lain berisi data karyawan lain, sehingga tidak ikut diekspor.
+	rows, err = database.DB.Query(ctx, `
+        SELECT `+auditColumns+` FROM audit_logs
+        WHERE (target_type = 'user' AND target_id = $1) OR actor_id::TEXT = $1
+        ORDER BY created_at ASC`, userID)
+	if err != nil {
+		return data, fmt.Errorf("fetch audit logs: %w", err)
+	}
+	for rows.Next() {
+		entry, err := scanAuditLog(rows)
+		if err != nil {
+			rows.Close()
+			return data, fmt.Errorf("scan audit log: %w", err)
+		}
+		if entry.TargetType != "user" || entry.TargetID != userID {
+			entry.Before, entry.After = nil, nil
+		}
+		data.AuditLogs = append(data.AuditLogs, entry)
+	}
+	rows.Close()
+
+	rows, err = database.DB.Query(ctx, `SELECT `+erasureColumns+` FROM erasure_requests WHERE user_id = $1 ORDER BY created_at ASC`, userID)
+	if err != nil {
+		return data, fmt.Errorf("fetch erasure requests: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		req, err := scanErasureRequest(rows)
+		if err != nil {
+			return data, fmt.Errorf("scan erasure request: %w", err)
+		}
+		data.ErasureRequests = append(data.ErasureRequests, req)
+	}
+	return data, rows.Err()
+}
+
+// writePersonalDataZip menulis bundle ZIP berisi data.json lengkap dan CSV kehadiran agar mudah dibaca
+func writePersonalDataZip(w io.Writer, data models.PersonalDataExport) error {
+	archive := zip.NewWriter(w)
+
+	f, err := archive.Create("data.json")
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(data); err != nil {
+		return err
+	}
+
+	f, err = archive.Create("attendance.csv")
+	if err != nil {
+		return err
+	}
+	table := utils.NewCSVWriter(f)
+	table.WriteRow("id", "check_in", "check_out", "latitude", "longitude", "status")
+	for _, att := range data.Attendance {
+		if err := table.WriteRow(att.ID, att.CheckIn, att.CheckOut, att.Latitude, att.Longitude, att.Status); err != nil {
+			return err
+		}
+	}
+	if err := table.Close(); err != nil {
+		return err
+	}
+
+	f, err = archive.Create("attendance_logs.csv")
+	if err != nil {
+		return err
+	}
+	table = utils.NewCSVWriter(f)
+	table.WriteRow("id", "attendance_id", "created_at", "type", "source", "latitude", "longitude", "location_name", "notes")
+	for _, entry := range data.AttendanceLogs {
+		var lat, lon interface{} = "", ""
+		if entry.Latitude != nil {
+			lat = *entry.Latitude
+		}
+		if entry.Longitude != nil {
+			lon = *entry.Longitude
+		}
+		err := table.WriteRow(entry.ID, entry.AttendanceID, entry.CreatedAt, entry.Type, entry.Source, lat, lon,
+			entry.LocationName, entry.Notes)
+		if err != nil {
+			return err
+		}
+	}
+	if err := table.Close(); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+// servePersonalDataExport mengirim data pribadi userID sebagai JSON (default) atau ZIP (?format=zip)
+func servePersonalDataExport(w http.ResponseWriter, r *http.Request, userID string) {
+	data, err := collectPersonalData(r.Context(), userID)
+	if err != nil {
+		log.Println("Error collecting personal data:", err)
+		http.Error(w, "Failed to export personal data", http.StatusInternalServerError)
+		return
+	}
+
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format != "zip" {
+		format = formatJSON
+	}
+	recordAudit(r, models.AuditDataExported, "user", userID, nil, map[string]string{"format": format})
+
+	filename := "personal-data-" + userID + "-" + data.GeneratedAt.Format("20060102")
+	if format == "zip" {
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`.zip"`)
+		if err := writePersonalDataZip(w, data); err != nil {
+			log.Println("Error writing personal data ZIP:", err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`.json"`)
+	json.NewEncoder(w).Encode(data)
+}
+
+// ExportMyData mengunduh semua data pribadi user yang login (hak akses subjek data)
+func ExportMyData(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok || userID == "" {
+		http.Error(w, "User ID is missing", http.StatusUnauthorized)
+		return
+	}
+	servePersonalDataExport(w, r, userID)
+}
+
+// ExportUserData mengunduh semua data pribadi seorang karyawan atas permintaannya (admin)
+func ExportUserData(w http.ResponseWriter, r *http.Request) {
+	userID := mux.Vars(r)["id"]
+
+	var exists bool
+	if err := database.DB.QueryRow(r.Context(), "SELECT EXISTS (SELECT 1 FROM users WHERE id = $1)", userID).Scan(&exists); err != nil || !exists {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+	servePersonalDataExport(w, r, userID)
+}
+
+// CreateErasureRequest mengajukan penghapusan data pribadi user yang login, diproses oleh admin
+func CreateErasureRequest(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok || userID == "" {
+		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	var data struct {
+		Reason string `json:"reason"`
+	}
+	// Body boleh kosong, alasan bersifat opsional
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil && err != io.EOF {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	// Hanya satu permintaan pending atau terjadwal per user
+	query := `
+        INSERT INTO erasure_requests (user_id, reason, status, created_at)
+        SELECT $1, $2, $3, NOW()
+        WHERE NOT EXISTS (SELECT 1 FROM erasure_requests WHERE user_id = $1 AND status IN ($3, $4))
+        RETURNING ` + erasureColumns
+	req, err := scanErasureRequest(database.DB.QueryRow(r.Context(), query, userID, strings.TrimSpace(data.Reason),
+		models.ErasureStatusPending, models.ErasureStatusScheduled))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			http.Error(w, "An erasure request is already pending", http.StatusConflict)
+			return
+		}
+		log.Println("Error creating erasure request:", err)
+		http.Error(w, "Failed to create erasure request", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(req)
+}
+
+// GetErasureRequests mengembalikan permintaan penghapusan data, bisa difilter dengan ?status=
+func GetErasureRequests(w http.ResponseWriter, r *http.Request) {
+	rows, err := database.DB.Query(r.Context(), `
+        SELECT `+erasureColumns+` FROM erasure_requests
+        WHERE ($1 = '' OR status = $1)
+        ORDER BY created_at DESC`, r.URL.Query().Get("status"))
+	if err != nil {
+		log.Println("Error fetching erasure requests:", err)
+		http.Error(w, "Failed to fetch erasure requests", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	requests := []models.ErasureRequest{}
+	for rows.Next() {
+		req, err := scanErasureRequest(rows)
+		if err != nil {
+			log.Println("Error scanning erasure request:", err)
+			http.Error(w, "Error scanning data", http.StatusInternalServerError)
+			return
+		}
+		requests = append(requests, req)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(requests)
+}
+
+// DecideErasureRequest menyetujui atau menolak permintaan penghapusan data.
+// Jika ERASURE_RETENTION_DAYS diisi, permintaan yang disetujui berstatus scheduled dan dijalankan job
+// retensi setelah masa retensi wajib selesai; tanpa konfigurasi, data langsung dianonimkan.
+// Anonimisasi menghapus profil dan koordinat lokasi user; catatan waktu kehadiran tetap disimpan
+// untuk kebutuhan penggajian. Perubahan data dan status permintaan disimpan dalam satu transaksi.
+func DecideErasureRequest(w http.ResponseWriter, r *http.Request) {
+	adminID, _ := r.Context().Value("user_id").(string)
+	requestID := mux.Vars(r)["id"]
+
+	var data struct {
+		Status string `json:"status"`
+		Note   string `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	if data.Status != models.ErasureStatusCompleted && data.Status != models.ErasureStatusRejected {
+		http.Error(w, "Status must be completed or rejected", http.StatusBadRequest)
+		return
+	}
+	if data.Status == models.ErasureStatusRejected && strings.TrimSpace(data.Note) == "" {
+		http.Error(w, "A note explaining the rejection is required", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := database.DB.Begin(r.Context())
+	if err != nil {
+		log.Println("Error starting erasure transaction:", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	var userID, currentStatus string
+	err = tx.QueryRow(r.Context(), "SELECT user_id::TEXT, status FROM erasure_requests WHERE id = $1 FOR UPDATE", requestID).Scan(&userID, &currentStatus)
+	if err != nil {
+		http.Error(w, "Erasure request not found", http.StatusNotFound)
+		return
+	}
+	if currentStatus != models.ErasureStatusPending {
+		http.Error(w, "Erasure request has already been decided", http.StatusConflict)
+		return
+	}
+
+	status := data.Status
+	retentionDays := utils.GetRetentionPolicy().ErasureRetentionDays
+	if status == models.ErasureStatusCompleted {
+		if retentionDays > 0 {
+			status = models.ErasureStatusScheduled
+		} else if err := jobs.AnonymizeUser(r.Context(), tx, userID, false); err != nil && err != jobs.ErrUserNotPurgeable {
+			log.Println("Error erasing user data:", err)
+			http.Error(w, "Failed to erase user data", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	query := `
+        UPDATE erasure_requests SET status = $1, note = $2, reviewed_by = $3, reviewed_at = NOW(),
+            scheduled_for = CASE WHEN $1 = $5 THEN NOW() + make_interval(days => $6) END
+        WHERE id = $4
+        RETURNING ` + erasureColumns
+	req, err := scanErasureRequest(tx.QueryRow(r.Context(), query, status, strings.TrimSpace(data.Note), adminID, requestID,
+		models.ErasureStatusScheduled, retentionDays))
+	if err == nil {
+		err = tx.Commit(r.Context())
+	}
+	if err != nil {
+		log.Println("Error deciding erasure request:", err)
+		http.Error(w, "Failed to update erasure request", http.StatusInternalServerError)
+		return
+	}
+
+	after := map[string]interface{}{"status": status, "request_id": requestID}
+	if req.ScheduledFor != nil {
+		after["scheduled_for"] = req.ScheduledFor
+	}
+	recordAudit(r, models.AuditErasureDecided, "user", userID, map[string]string{"status": currentStatus}, after)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(req)
+}
@@ -0,0 +1,270 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"absensi/database"
+	"absensi/models"
+	"absensi/notify"
+	"absensi/utils"
+)
+
+// getProfile mengambil data user tanpa hash password
+func getProfile(ctx context.Context, userID string) (models.User, error) {
+	var user models.User
+	user.NotificationPreferences = &models.NotificationPreferences{}
+
+	query := `
+        SELECT id::TEXT, name, email, role, department_id::TEXT, team_id::TEXT, manager_id::TEXT,
+               COALESCE(employee_number, ''), COALESCE(site, ''), COALESCE(status, 'active'),
+               COALESCE(phone, ''), COALESCE(avatar_url, ''), COALESCE(language, 'id'),
+               COALESCE(notification_preferences, '{}'::JSONB), created_at
+        FROM users WHERE id = $1`
+	err := database.DB.QueryRow(ctx, query, userID).Scan(&user.ID, &user.Name, &user.Email, &user.Role,
+		&user.DepartmentID, &user.TeamID, &user.ManagerID, &user.EmployeeNumber, &user.Site, &user.Status,
+		&user.Phone, &user.AvatarURL, &user.Language, user.NotificationPreferences, &user.CreatedAt)
+	*user.NotificationPreferences = user.NotificationPreferences.Resolved()
+	return user, err
+}
+
+// mergeNotificationPreferences menimpa pengaturan tersimpan (sudah Resolved) dengan event yang dikirim user.
+// Pengaturan lama "email": false tetap dihormati dengan mematikan semua event ber-channel email.
+func mergeNotificationPreferences(stored, submitted models.NotificationPreferences) models.NotificationPreferences {
+	merged := models.NotificationPreferences{Events: map[string]string{}}
+	for event, channel := range stored.Events {
+		if channel == models.ChannelEmail && submitted.Email != nil && !*submitted.Email {
+			channel = models.ChannelNone
+		}
+		merged.Events[event] = channel
+	}
+	for event, channel := range submitted.Events {
+		merged.Events[event] = channel
+	}
+	return merged
+}
+
+// validateNotificationEvents memastikan setiap event dan channel dikenal
+func validateNotificationEvents(events map[string]string) error {
+	for event, channel := range events {
+		if _, ok := models.NotifyEvents[event]; !ok {
+			return fmt.Errorf("Unknown notification event %q", event)
+		}
+		if channel != models.ChannelEmail && channel != models.ChannelPush && channel != models.ChannelNone {
+			return fmt.Errorf("Channel for %q must be email, push or none", event)
+		}
+		if channel == models.ChannelPush && notify.Push == nil {
+			return fmt.Errorf("Push notifications are not available for %q", event)
+		}
+	}
+	return nil
+}
+
+// GetMe mengembalikan profil user yang login
+func GetMe(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok || userID == "" {
+		http.Error(w, "User ID is missing", http.StatusUnauthorized)
+		return
+	}
+
+	user, err := getProfile(r.Context(), userID)
+	if err != nil {
+		log.Println("Error fetching profile:", err)
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+// UpdateMe mengubah sebagian profil user yang login: name, phone, avatar_url, language dan notification_preferences
+func UpdateMe(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok || userID == "" {
+		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	// Field nil berarti tidak diubah
+	var data struct {
+		Name                    *string                         `json:"name"`
+		Phone                   *string                         `json:"phone"`
+		AvatarURL               *string                         `json:"avatar_url"`
+		Language                *string                         `json:"language"`
+		NotificationPreferences *models.NotificationPreferences `json:"notification_preferences"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	if data.Name != nil {
+		trimmed := strings.TrimSpace(*data.Name)
+		if trimmed == "" {
+			http.Error(w, "Name cannot be empty", http.StatusBadRequest)
+			return
+		}
+		data.Name = &trimmed
+	}
+	if data.AvatarURL != nil && *data.AvatarURL != "" &&
+		!strings.HasPrefix(*data.AvatarURL, "https://") && !strings.HasPrefix(*data.AvatarURL, "http://") {
+		http.Error(w, "Avatar URL must be an http(s) URL", http.StatusBadRequest)
+		return
+	}
+
+	if data.NotificationPreferences != nil {
+		if err := validateNotificationEvents(data.NotificationPreferences.Events); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		// Gabungkan dengan pengaturan tersimpan agar event yang tidak disebut tidak kembali ke default
+		current, err := getProfile(r.Context(), userID)
+		if err != nil {
+			log.Println("Error fetching profile:", err)
+			http.Error(w, "User not found", http.StatusNotFound)
+			return
+		}
+		merged := mergeNotificationPreferences(*current.NotificationPreferences, *data.NotificationPreferences)
+		data.NotificationPreferences = &merged
+	}
+	if data.Language != nil && notify.NormalizeLanguage(*data.Language) != *data.Language {
+		http.Error(w, "Language must be id or en", http.StatusBadRequest)
+		return
+	}
+
+	query := `
+        UPDATE users SET
+            name = COALESCE($1, name),
+            phone = COALESCE($2, phone),
+            avatar_url = COALESCE($3, avatar_url),
+            language = COALESCE($4, language),
+            notification_preferences = COALESCE($5, notification_preferences)
+        WHERE id = $6`
+	_, err := database.DB.Exec(r.Context(), query, data.Name, data.Phone, data.AvatarURL, data.Language, data.NotificationPreferences, userID)
+	if err != nil {
+		log.Println("Error updating profile:", err)
+		http.Error(w, "Failed to update profile", http.StatusInternalServerError)
+		return
+	}
+
+	user, err := getProfile(r.Context(), userID)
+	if err != nil {
+		log.Println("Error fetching profile:", err)
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+// ChangePassword mengganti password user yang login, wajib menyertakan password lama
+func ChangePassword(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok || userID == "" {
+		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	var data struct {
+		CurrentPassword string `json:"current_password"`
+		NewPassword     string `json:"new_password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	if len(data.NewPassword) < 8 {
+		http.Error(w, "Password must be at least 8 characters", http.StatusBadRequest)
+		return
+	}
+
+	var storedPassword string
+	err := database.DB.QueryRow(r.Context(), "SELECT password FROM users WHERE id = $1", userID).Scan(&storedPassword)
+	if err != nil {
+		log.Println("Error fetching password:", err)
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	if !utils.CheckPasswordHash(data.CurrentPassword, storedPassword) {
+		http.Error(w, "Current password is incorrect", http.StatusUnauthorized)
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(data.NewPassword)
+	if err != nil {
+		http.Error(w, "Password hashing failed", http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := database.DB.Exec(r.Context(), "UPDATE users SET password = $1 WHERE id = $2", hashedPassword, userID); err != nil {
+		log.Println("Error updating password:", err)
+		http.Error(w, "Failed to change password", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Password changed"})
+}
+
+// GetNotificationPreferences mengembalikan channel efektif setiap event notifikasi user yang login
+func GetNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok || userID == "" {
+		http.Error(w, "User ID is missing", http.StatusUnauthorized)
+		return
+	}
+
+	user, err := getProfile(r.Context(), userID)
+	if err != nil {
+		log.Println("Error fetching profile:", err)
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user.NotificationPreferences)
+}
+
+// UpdateNotificationPreferences mengubah channel sebagian event, contoh {"events": {"check_in_receipt": "none"}}.
+// Event yang tidak disebut tetap memakai pengaturan sebelumnya.
+func UpdateNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok || userID == "" {
+		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	var data models.NotificationPreferences
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	if err := validateNotificationEvents(data.Events); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := getProfile(r.Context(), userID)
+	if err != nil {
+		log.Println("Error fetching profile:", err)
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+	prefs := mergeNotificationPreferences(*user.NotificationPreferences, data)
+
+	if _, err := database.DB.Exec(r.Context(), "UPDATE users SET notification_preferences = $1 WHERE id = $2", prefs, userID); err != nil {
+		log.Println("Error updating notification preferences:", err)
+		http.Error(w, "Failed to update notification preferences", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(prefs)
+}
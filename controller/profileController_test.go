@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"absensi/models"
+	"absensi/notify"
+)
+
+func TestMergeNotificationPreferences(t *testing.T) {
+	off := false
+	stored := models.NotificationPreferences{Events: map[string]string{
+		models.NotifyCheckInReceipt:   models.ChannelNone,
+		models.NotifyLeaveDecision:    models.ChannelEmail,
+		models.NotifyCheckoutReminder: models.ChannelPush,
+	}}
+
+	tests := []struct {
+		name      string
+		submitted models.NotificationPreferences
+		want      map[string]string
+	}{
+		{
+			name:      "event lain tetap memakai pengaturan tersimpan",
+			submitted: models.NotificationPreferences{Events: map[string]string{models.NotifyLeaveDecision: models.ChannelNone}},
+			want: map[string]string{
+				models.NotifyCheckInReceipt:   models.ChannelNone,
+				models.NotifyLeaveDecision:    models.ChannelNone,
+				models.NotifyCheckoutReminder: models.ChannelPush,
+			},
+		},
+		{
+			name:      "pengaturan lama email false mematikan event email",
+			submitted: models.NotificationPreferences{Email: &off},
+			want: map[string]string{
+				models.NotifyCheckInReceipt:   models.ChannelNone,
+				models.NotifyLeaveDecision:    models.ChannelNone,
+				models.NotifyCheckoutReminder: models.ChannelPush,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mergeNotificationPreferences(stored, tt.submitted)
+			if len(got.Events) != len(tt.want) {
+				t.Fatalf("got %d events, want %d", len(got.Events), len(tt.want))
+			}
+			for event, channel := range tt.want {
+				if got.Events[event] != channel {
+					t.Errorf("%s = %q, want %q", event, got.Events[event], channel)
+				}
+			}
+		})
+	}
+	if stored.Events[models.NotifyLeaveDecision] != models.ChannelEmail {
+		t.Error("stored preferences were modified")
+	}
+}
+
+type fakePush struct{}
+
+func (fakePush) SendPush(ctx context.Context, token string, msg notify.Message) error { return nil }
+
+func TestValidateNotificationEventsPush(t *testing.T) {
+	events := map[string]string{models.NotifyLeaveDecision: models.ChannelPush}
+	defer func(p notify.PushNotifier) { notify.Push = p }(notify.Push)
+
+	notify.Push = nil
+	if err := validateNotificationEvents(events); err == nil {
+		t.Error("push accepted while push notifications are not configured")
+	}
+
+	notify.Push = fakePush{}
+	if err := validateNotificationEvents(events); err != nil {
+		t.Errorf("push rejected while configured: %v", err)
+	}
+}
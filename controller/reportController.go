@@ -0,0 +1,335 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"time"
+
+	"absensi/database"
+	"absensi/models"
+	"absensi/utils"
+)
+
+var monthNames = []string{"", "Januari", "Februari", "Maret", "April", "Mei", "Juni", "Juli",
+	"Agustus", "September", "Oktober", "November", "Desember"}
+
+var dayNames = []string{"Minggu", "Senin", "Selasa", "Rabu", "Kamis", "Jumat", "Sabtu"}
+
+// buildMonthlyRecaps menyusun rekap satu bulan penuh, lihat buildRecaps
+func buildMonthlyRecaps(ctx context.Context, month, year int, all bool, userIDs []string, departmentID string) ([]models.MonthlyRecap, error) {
+	from := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.Local)
+	return buildRecaps(ctx, from, from.AddDate(0, 1, 0), all, userIDs, departmentID)
+}
+
+// buildRecaps menyusun rekap lengkap (satu baris per tanggal dalam [from, to)) untuk user dalam scope,
+// opsional hanya untuk satu department. Lihat assembleRecap untuk status setiap tanggal.
+func buildRecaps(ctx context.Context, from, to time.Time, all bool, userIDs []string, departmentID string) ([]models.MonthlyRecap, error) {
+	query := `
+        SELECT u.id::TEXT, u.name, COALESCE(d.id::TEXT, ''), COALESCE(d.name, ''), u.created_at, u.deleted_at
+        FROM users u
+        LEFT JOIN departments d ON d.id = u.department_id
+        WHERE ($1 OR u.id::TEXT = ANY($2)) AND ($3 = '' OR u.department_id::TEXT = $3)
+          AND u.purged_at IS NULL AND u.created_at < $5 AND (u.deleted_at IS NULL OR u.deleted_at > $4)
+        ORDER BY u.name ASC`
+
+	// User yang sudah dianonimkan, atau yang masa kerjanya tidak beririsan dengan periode, tidak ikut direkap
+	rows, err := database.DB.Query(ctx, query, all, userIDs, departmentID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	recaps := []models.MonthlyRecap{}
+	employed := []employment{}
+	ids := []string{}
+	for rows.Next() {
+		var recap models.MonthlyRecap
+		var e employment
+		if err := rows.Scan(&recap.UserID, &recap.Name, &recap.DepartmentID, &recap.DepartmentName, &e.from, &e.until); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, recap.UserID)
+		recaps = append(recaps, recap)
+		employed = append(employed, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(recaps) == 0 {
+		return recaps, nil
+	}
+
+	// Kehadiran per user per tanggal
+	attended := map[string]map[string]models.DailyAttendance{}
+	err = fetchDailyAttendance(ctx, from, to, false, ids, func(day models.DailyAttendance) error {
+		if attended[day.UserID] == nil {
+			attended[day.UserID] = map[string]models.DailyAttendance{}
+		}
+		attended[day.UserID][day.Date.Format("2006-01-02")] = day
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Tanggal cuti yang sudah disetujui
+	leaves := map[string]map[string]string{}
+	leaveQuery := `
+        SELECT user_id::TEXT, type, start_date, end_date FROM leave_requests
+        WHERE status = $1 AND user_id::TEXT = ANY($2) AND start_date < $4::DATE AND end_date >= $3::DATE`
+	leaveRows, err := database.DB.Query(ctx, leaveQuery, models.LeaveStatusApproved, ids, from, to)
+	if err != nil {
+		return nil, err
+	}
+	for leaveRows.Next() {
+		var userID, leaveType string
+		var start, end time.Time
+		if err := leaveRows.Scan(&userID, &leaveType, &start, &end); err != nil {
+			leaveRows.Close()
+			return nil, err
+		}
+		if leaves[userID] == nil {
+			leaves[userID] = map[string]string{}
+		}
+		for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+			leaves[userID][d.Format("2006-01-02")] = leaveType
+		}
+	}
+	leaveRows.Close()
+	if err := leaveRows.Err(); err != nil {
+		return nil, err
+	}
+
+	today := time.Now()
+	for i := range recaps {
+		userID := recaps[i].UserID
+		assembleRecap(&recaps[i], from, to, today, employed[i], attended[userID], leaves[userID])
+	}
+
+	return recaps, nil
+}
+
+// employment adalah masa kerja user: sejak dibuat sampai dihapus (until nil jika masih ada)
+type employment struct {
+	from  time.Time
+	until *time.Time
+}
+
+// covers mengecek apakah tanggal day (awal hari) berada dalam masa kerja
+func (e employment) covers(day time.Time) bool {
+	if !day.AddDate(0, 0, 1).After(e.from) {
+		return false
+	}
+	return e.until == nil || day.Before(*e.until)
+}
+
+// assembleRecap mengisi satu baris per tanggal dalam [from, to) dan totalnya dari kehadiran dan cuti user.
+// Hari kerja tanpa kehadiran dihitung absen kecuali sedang cuti; tanggal di luar masa kerja tidak dihitung.
+func assembleRecap(recap *models.MonthlyRecap, from, to, today time.Time, e employment,
+	attended map[string]models.DailyAttendance, leaves map[string]string) {
+	recap.Days = []models.DailyAttendance{}
+
+	for d := from; d.Before(to); d = d.AddDate(0, 0, 1) {
+		key := d.Format("2006-01-02")
+		day, ok := attended[key]
+		if !ok || !e.covers(d) {
+			day = models.DailyAttendance{UserID: recap.UserID, Name: recap.Name, DepartmentName: recap.DepartmentName, Date: d}
+			leaveType, onLeave := leaves[key]
+			switch {
+			case !e.covers(d):
+				day.Status = models.DailyStatusNotEmployed
+			case onLeave:
+				day.Status = models.DailyStatusLeave
+				day.LeaveType = leaveType
+			case d.Weekday() == time.Saturday || d.Weekday() == time.Sunday:
+				day.Status = models.DailyStatusOff
+			case d.After(today):
+				// Hari yang belum terjadi tidak dihitung absen
+			default:
+				day.Status = models.DailyStatusAbsent
+			}
+		}
+		day.Date = d
+
+		switch day.Status {
+		case models.DailyStatusPresent, models.DailyStatusNoCheckOut:
+			recap.Totals.Present++
+		case models.DailyStatusLate:
+			recap.Totals.Present++
+			recap.Totals.Late++
+		case models.DailyStatusAbsent:
+			recap.Totals.Absent++
+		case models.DailyStatusLeave:
+			recap.Totals.Leave++
+		}
+		recap.Totals.LateMinutes += day.LateMinutes
+		recap.Totals.WorkedHours += day.WorkedHours
+		recap.Totals.OvertimeHours += day.OvertimeHours
+
+		recap.Days = append(recap.Days, day)
+	}
+	recap.Totals.WorkedHours = math.Round(recap.Totals.WorkedHours*100) / 100
+	recap.Totals.OvertimeHours = math.Round(recap.Totals.OvertimeHours*100) / 100
+}
+
+// GetMonthlyReportPDF membuat rekap absensi bulanan dalam bentuk PDF.
+// Tanpa parameter: rekap milik user yang login. ?user_id= untuk bawahan, ?department_id= untuk satu department.
+func GetMonthlyReportPDF(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok || userID == "" {
+		http.Error(w, "User ID is missing", http.StatusUnauthorized)
+		return
+	}
+
+	month, year, err := parseMonthYear(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	targetID := r.URL.Query().Get("user_id")
+	departmentID := r.URL.Query().Get("department_id")
+
+	all, scopeIDs := false, []string{userID}
+	switch {
+	case departmentID != "":
+		all, scopeIDs, err = attendanceScope(r.Context(), userID)
+	case targetID != "" && targetID != userID:
+		var allowed bool
+		allowed, err = isInScope(r.Context(), userID, targetID)
+		if err == nil && !allowed {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		scopeIDs = []string{targetID}
+	}
+	if err != nil {
+		log.Println("Error resolving attendance scope:", err)
+		http.Error(w, "Failed to resolve attendance scope", http.StatusInternalServerError)
+		return
+	}
+
+	recaps, err := buildMonthlyRecaps(r.Context(), month, year, all, scopeIDs, departmentID)
+	if err != nil {
+		log.Println("Error building monthly recap:", err)
+		http.Error(w, "Failed to build report", http.StatusInternalServerError)
+		return
+	}
+	if len(recaps) == 0 {
+		http.Error(w, "No users found for report", http.StatusNotFound)
+		return
+	}
+
+	pdf := utils.NewPDF()
+	for _, recap := range recaps {
+		renderRecapPage(pdf, recap, month, year)
+	}
+
+	var buf bytes.Buffer
+	if _, err := pdf.WriteTo(&buf); err != nil {
+		log.Println("Error rendering PDF:", err)
+		http.Error(w, "Failed to render report", http.StatusInternalServerError)
+		return
+	}
+
+	filename := fmt.Sprintf("rekap-absensi-%04d-%02d.pdf", year, month)
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.Write(buf.Bytes())
+}
+
+// renderRecapPage menggambar satu halaman rekap untuk satu user
+func renderRecapPage(pdf *utils.PDF, recap models.MonthlyRecap, month, year int) {
+	pdf.AddPage()
+
+	company := os.Getenv("COMPANY_NAME")
+	if company == "" {
+		company = "Absensi App"
+	}
+	pdf.Text(40, 50, 14, true, company)
+	if site := os.Getenv("COMPANY_SITE"); site != "" {
+		pdf.Text(40, 66, 9, false, site)
+	}
+	pdf.Text(40, 90, 12, true, fmt.Sprintf("Rekap Absensi Bulanan - %s %d", monthNames[month], year))
+	pdf.Text(40, 108, 10, false, "Nama: "+recap.Name)
+	department := recap.DepartmentName
+	if department == "" {
+		department = "-"
+	}
+	pdf.Text(300, 108, 10, false, "Departemen: "+department)
+
+	// Tabel harian
+	columns := []struct {
+		title string
+		x     float64
+	}{
+		{"Tanggal", 40}, {"Hari", 105}, {"Masuk", 160}, {"Pulang", 215}, {"Status", 270},
+		{"Telat (mnt)", 350}, {"Jam Kerja", 420}, {"Lembur", 490},
+	}
+	y := 130.0
+	pdf.Line(40, y-10, 555, y-10)
+	for _, col := range columns {
+		pdf.Text(col.x, y, 8, true, col.title)
+	}
+	pdf.Line(40, y+4, 555, y+4)
+
+	clock := func(t *time.Time) string {
+		if t == nil {
+			return "-"
+		}
+		return t.In(time.Local).Format("15:04")
+	}
+	statusLabels := map[string]string{
+		models.DailyStatusPresent:    "Hadir",
+		models.DailyStatusLate:       "Terlambat",
+		models.DailyStatusNoCheckOut: "Tidak pulang",
+		models.DailyStatusAbsent:     "Absen",
+		models.DailyStatusLeave:      "Cuti",
+		models.DailyStatusOff:        "Libur",
+	}
+
+	for _, day := range recap.Days {
+		y += 14
+		status := statusLabels[day.Status]
+		if status == "" {
+			status = "-"
+		}
+		values := []string{
+			day.Date.Format("02-01-2006"), dayNames[day.Date.Weekday()], clock(day.CheckIn), clock(day.CheckOut), status,
+			fmt.Sprint(day.LateMinutes), fmt.Sprintf("%.2f", day.WorkedHours), fmt.Sprintf("%.2f", day.OvertimeHours),
+		}
+		for i, col := range columns {
+			pdf.Text(col.x, y, 8, false, values[i])
+		}
+	}
+	pdf.Line(40, y+6, 555, y+6)
+
+	// Total
+	y += 26
+	pdf.Text(40, y, 10, true, "Total")
+	y += 16
+	pdf.Text(40, y, 9, false, fmt.Sprintf("Hadir: %d hari", recap.Totals.Present))
+	pdf.Text(170, y, 9, false, fmt.Sprintf("Terlambat: %d hari (%d menit)", recap.Totals.Late, recap.Totals.LateMinutes))
+	pdf.Text(360, y, 9, false, fmt.Sprintf("Absen: %d hari", recap.Totals.Absent))
+	y += 14
+	pdf.Text(40, y, 9, false, fmt.Sprintf("Cuti: %d hari", recap.Totals.Leave))
+	pdf.Text(170, y, 9, false, fmt.Sprintf("Jam kerja: %.2f jam", recap.Totals.WorkedHours))
+	pdf.Text(360, y, 9, false, fmt.Sprintf("Lembur: %.2f jam", recap.Totals.OvertimeHours))
+
+	// Kolom tanda tangan
+	y += 40
+	pdf.Text(40, y, 9, false, "Karyawan,")
+	pdf.Text(380, y, 9, false, "Mengetahui,")
+	y += 50
+	pdf.Line(40, y, 180, y)
+	pdf.Line(380, y, 520, y)
+	pdf.Text(40, y+12, 9, false, recap.Name)
+	pdf.Text(380, y+12, 9, false, "Atasan / HRD")
+
+	pdf.Text(40, utils.PageHeight-30, 7, false, "Dicetak "+time.Now().Format("02-01-2006 15:04"))
+}
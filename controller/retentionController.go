@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"absensi/database"
+	"absensi/jobs"
+	"absensi/models"
+	"absensi/utils"
+)
+
+// GetRetentionPolicy mengembalikan kebijakan retensi lokasi yang sedang berlaku
+func GetRetentionPolicy(w http.ResponseWriter, r *http.Request) {
+	policy := utils.GetRetentionPolicy()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"enabled":          policy.Enabled(),
+		"policy":           policy,
+		"interval_seconds": int(policy.Interval.Seconds()),
+	})
+}
+
+// RunRetentionNow menjalankan job retensi lokasi saat ini juga.
+// ?dry_run=true hanya menghitung baris yang akan diproses tanpa mengubah data.
+func RunRetentionNow(w http.ResponseWriter, r *http.Request) {
+	policy := utils.GetRetentionPolicy()
+	if !policy.Enabled() {
+		http.Error(w, "Retention policy is not configured", http.StatusConflict)
+		return
+	}
+
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+	run, err := jobs.RunRetention(r.Context(), policy, models.RetentionTriggerManual, dryRun)
+	if err == jobs.ErrRetentionRunning {
+		http.Error(w, "Retention job is already running", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Println("Error running location retention:", err)
+		http.Error(w, "Failed to run retention job", http.StatusInternalServerError)
+		return
+	}
+
+	if !dryRun {
+		recordAudit(r, models.AuditRetentionRun, "retention_run", run.ID, nil, run)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(run)
+}
+
+// GetRetentionRuns mengembalikan laporan 50 putaran retensi terakhir
+func GetRetentionRuns(w http.ResponseWriter, r *http.Request) {
+	query := `
+        SELECT id::TEXT, trigger, coarsen_before, delete_before, coarsen_decimals,
+               logs_coarsened, attendance_coarsened, logs_cleared, attendance_cleared, started_at, finished_at
+        FROM retention_runs
+        ORDER BY started_at DESC
+        LIMIT 50`
+	rows, err := database.DB.Query(r.Context(), query)
+	if err != nil {
+		log.Println("Error fetching retention runs:", err)
+		http.Error(w, "Failed to fetch retention runs", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	runs := []models.RetentionRun{}
+	for rows.Next() {
+		var run models.RetentionRun
+		err := rows.Scan(&run.ID, &run.Trigger, &run.CoarsenBefore, &run.DeleteBefore, &run.CoarsenDecimals,
+			&run.LogsCoarsened, &run.AttendanceCoarsened, &run.LogsCleared, &run.AttendanceCleared, &run.StartedAt, &run.FinishedAt)
+		if err != nil {
+			log.Println("Error scanning retention run:", err)
+			http.Error(w, "Error scanning data", http.StatusInternalServerError)
+			return
+		}
+		runs = append(runs, run)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(runs)
+}
@@ -3,22 +3,102 @@ package controller
 import (
 	"context"
 	"encoding/json"
-
-	// "encoding/json"
+	"fmt"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
 
-	// "github.com/gorilla/mux"
 	"absensi/database"
+	"absensi/jobs"
 	"absensi/models"
 
 	"github.com/gorilla/mux"
-	"github.com/jackc/pgx/v5/pgtype"
 )
 
+// Kolom yang boleh dipakai untuk sort pada GetUsers
+var userSortColumns = map[string]string{
+    "name":       "u.name",
+    "email":      "u.email",
+    "role":       "u.role",
+    "created_at": "u.created_at",
+}
+
+// GetUsers mengembalikan daftar user dengan pagination (page, page_size), pencarian q (nama/email),
+// filter role, department_id, site, status dan sort (name|email|role|created_at) + order (asc|desc)
 func GetUsers(w http.ResponseWriter, r *http.Request) {
-    query := "SELECT id, name, email, role, created_at FROM users"
-    rows, err := database.DB.Query(context.Background(), query)
+    params := r.URL.Query()
+
+    page, err := strconv.Atoi(params.Get("page"))
+    if err != nil || page < 1 {
+        page = 1
+    }
+    pageSize, err := strconv.Atoi(params.Get("page_size"))
+    if err != nil || pageSize < 1 {
+        pageSize = 20
+    }
+    if pageSize > 100 {
+        pageSize = 100
+    }
+
+    // Susun filter secara dinamis, nilai selalu lewat parameter query
+    conditions := []string{}
+    args := []interface{}{}
+    addCondition := func(format string, value interface{}) {
+        args = append(args, value)
+        conditions = append(conditions, fmt.Sprintf(format, len(args)))
+    }
+
+    if q := strings.TrimSpace(params.Get("q")); q != "" {
+        addCondition("(u.name ILIKE $%[1]d OR u.email ILIKE $%[1]d)", "%"+q+"%")
+    }
+    if role := params.Get("role"); role != "" {
+        addCondition("u.role = $%d", role)
+    }
+    if departmentID := params.Get("department_id"); departmentID != "" {
+        addCondition("u.department_id::TEXT = $%d", departmentID)
+    }
+    if site := params.Get("site"); site != "" {
+        addCondition("u.site = $%d", site)
+    }
+    if status := params.Get("status"); status != "" {
+        addCondition("COALESCE(u.status, 'active') = $%d", status)
+    }
+    // User yang sudah dihapus (soft delete) disembunyikan kecuali diminta
+    if params.Get("include_deleted") != "true" {
+        conditions = append(conditions, "u.deleted_at IS NULL")
+    }
+
+    where := ""
+    if len(conditions) > 0 {
+        where = "WHERE " + strings.Join(conditions, " AND ")
+    }
+
+    sortColumn, ok := userSortColumns[params.Get("sort")]
+    if !ok {
+        sortColumn = "u.name"
+    }
+    order := "ASC"
+    if strings.EqualFold(params.Get("order"), "desc") {
+        order = "DESC"
+    }
+
+    var total int
+    if err := database.DB.QueryRow(r.Context(), "SELECT COUNT(*) FROM users u "+where, args...).Scan(&total); err != nil {
+        log.Println("Error counting users:", err)
+        http.Error(w, "Failed to fetch users", http.StatusInternalServerError)
+        return
+    }
+
+    query := fmt.Sprintf(`
+        SELECT u.id::TEXT, u.name, u.email, u.role, u.department_id::TEXT, u.team_id::TEXT, u.manager_id::TEXT,
+               COALESCE(u.employee_number, ''), COALESCE(u.site, ''), COALESCE(u.status, 'active'), u.created_at, u.deleted_at
+        FROM users u
+        %s
+        ORDER BY %s %s, u.id ASC
+        LIMIT %d OFFSET %d`, where, sortColumn, order, pageSize, (page-1)*pageSize)
+
+    rows, err := database.DB.Query(r.Context(), query, args...)
     if err != nil {
         log.Println("Error fetching users:", err)
         http.Error(w, "Failed to fetch users", http.StatusInternalServerError)
@@ -26,24 +106,32 @@ func GetUsers(w http.ResponseWriter, r *http.Request) {
     }
     defer rows.Close()
 
-    var users []models.User
+    users := []models.User{}
     for rows.Next() {
         var user models.User
-        var id pgtype.UUID // Gunakan pgtype.UUID untuk membaca UUID dari database
-
-        err := rows.Scan(&id, &user.Name, &user.Email, &user.Role, &user.CreatedAt)
+        err := rows.Scan(&user.ID, &user.Name, &user.Email, &user.Role, &user.DepartmentID, &user.TeamID, &user.ManagerID,
+            &user.EmployeeNumber, &user.Site, &user.Status, &user.CreatedAt, &user.DeletedAt)
         if err != nil {
             log.Println("Error scanning user:", err)
-            continue
+            http.Error(w, "Error scanning data", http.StatusInternalServerError)
+            return
         }
-
-        user.ID = id.String() // Konversi UUID ke string
-
         users = append(users, user)
     }
 
+    if err := rows.Err(); err != nil {
+        log.Println("Error iterating rows:", err)
+        http.Error(w, "Failed to fetch users", http.StatusInternalServerError)
+        return
+    }
+
     w.Header().Set("Content-Type", "application/json")
-    json.NewEncoder(w).Encode(users)
+    json.NewEncoder(w).Encode(models.UserPage{
+        Data:     users,
+        Total:    total,
+        Page:     page,
+        PageSize: pageSize,
+    })
 }
 
 func UpdateUserRole(w http.ResponseWriter, r *http.Request) {
@@ -57,31 +145,149 @@ func UpdateUserRole(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid input", http.StatusBadRequest)
 		return
 	}
+	if !models.ValidRole(data.Role) {
+		http.Error(w, "Invalid role", http.StatusBadRequest)
+		return
+	}
+
+	// Role lama disimpan untuk audit log
+	var oldRole string
+	if err := database.DB.QueryRow(r.Context(), "SELECT role FROM users WHERE id = $1", userID).Scan(&oldRole); err != nil {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
 
 	query := "UPDATE users SET role = $1 WHERE id = $2"
-    _, err := database.DB.Exec(context.Background(), query, data.Role, userID)
+    _, err := database.DB.Exec(r.Context(), query, data.Role, userID)
     if err != nil {
         log.Println("Error updating user role:", err)
         http.Error(w, "Failed to update role", http.StatusInternalServerError)
         return
     }
 
+	recordAudit(r, models.AuditUserRoleChanged, "user", userID, map[string]string{"role": oldRole}, map[string]string{"role": data.Role})
+
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"message": "User role updated"})
 }
 
+// isActiveUser mengecek apakah user masih aktif dan belum dihapus
+func isActiveUser(ctx context.Context, userID string) (bool, error) {
+	var active bool
+	err := database.DB.QueryRow(ctx,
+		"SELECT COALESCE(status, 'active') = 'active' AND deleted_at IS NULL FROM users WHERE id = $1", userID).Scan(&active)
+	return active, err
+}
+
+// DeleteUser melakukan soft delete: data user dan riwayat kehadiran tetap disimpan untuk payroll dan audit
 func DeleteUser(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	userID := params["id"]
 
-	query := "DELETE FROM users WHERE id = $1"
-	_, err := database.DB.Exec(context.Background(), query, userID)
+	query := "UPDATE users SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL"
+	tag, err := database.DB.Exec(r.Context(), query, userID)
 	if err != nil {
 		log.Println("Error deleting user:", err)
 		http.Error(w, "Failed to delete user", http.StatusInternalServerError)
 		return
 	}
+	if tag.RowsAffected() == 0 {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	recordAudit(r, models.AuditUserDeleted, "user", userID, nil, nil)
 
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"message": "User deleted"})
-}
\ No newline at end of file
+}
+
+// setUserStatus mengaktifkan atau menonaktifkan user
+func setUserStatus(w http.ResponseWriter, r *http.Request, status, action, message string) {
+	userID := mux.Vars(r)["id"]
+
+	tag, err := database.DB.Exec(r.Context(), "UPDATE users SET status = $1 WHERE id = $2 AND deleted_at IS NULL", status, userID)
+	if err != nil {
+		log.Println("Error updating user status:", err)
+		http.Error(w, "Failed to update user status", http.StatusInternalServerError)
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	recordAudit(r, action, "user", userID, nil, map[string]string{"status": status})
+
+	json.NewEncoder(w).Encode(map[string]string{"message": message})
+}
+
+// DeactivateUser memblokir login dan check-in user tanpa menghapus datanya
+func DeactivateUser(w http.ResponseWriter, r *http.Request) {
+	setUserStatus(w, r, models.UserStatusInactive, models.AuditUserDeactivated, "User deactivated")
+}
+
+func ActivateUser(w http.ResponseWriter, r *http.Request) {
+	setUserStatus(w, r, models.UserStatusActive, models.AuditUserActivated, "User activated")
+}
+
+// RestoreUser membatalkan soft delete
+func RestoreUser(w http.ResponseWriter, r *http.Request) {
+	userID := mux.Vars(r)["id"]
+
+	tag, err := database.DB.Exec(r.Context(), "UPDATE users SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL AND purged_at IS NULL", userID)
+	if err != nil {
+		log.Println("Error restoring user:", err)
+		http.Error(w, "Failed to restore user", http.StatusInternalServerError)
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		http.Error(w, "Deleted user not found", http.StatusNotFound)
+		return
+	}
+
+	recordAudit(r, models.AuditUserRestored, "user", userID, nil, nil)
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "User restored"})
+}
+
+// PurgeUser menganonimkan data pribadi user yang sudah di-soft delete secara permanen.
+// Baris attendance tetap ada sehingga jumlah kehadiran untuk laporan tidak berubah,
+// tetapi koordinat, nama lokasi dan catatan dihapus. Wajib ?confirm=true.
+func PurgeUser(w http.ResponseWriter, r *http.Request) {
+	userID := mux.Vars(r)["id"]
+
+	if r.URL.Query().Get("confirm") != "true" {
+		http.Error(w, "Purge is irreversible, pass confirm=true", http.StatusBadRequest)
+		return
+	}
+
+	if err := anonymizeUser(r.Context(), userID, true); err != nil {
+		if err == jobs.ErrUserNotPurgeable {
+			http.Error(w, "Only soft-deleted users can be purged", http.StatusConflict)
+			return
+		}
+		log.Println("Error purging user:", err)
+		http.Error(w, "Failed to purge user", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, models.AuditUserPurged, "user", userID, nil, nil)
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "User purged"})
+}
+
+// anonymizeUser menghapus data pribadi user dalam satu transaksi (lihat jobs.AnonymizeUser).
+// Jika requireDeleted true, user harus sudah di-soft delete terlebih dahulu.
+func anonymizeUser(ctx context.Context, userID string, requireDeleted bool) error {
+	tx, err := database.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := jobs.AnonymizeUser(ctx, tx, userID, requireDeleted); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,402 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/mail"
+	"os"
+	"strings"
+
+	"absensi/database"
+	"absensi/jobs"
+	"absensi/models"
+	"absensi/utils"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Kolom CSV import/export user
+var userCSVColumns = []string{"name", "email", "role", "department", "site", "employee_number"}
+
+// parseUserCSV membaca CSV user berdasarkan nama kolom di header
+func parseUserCSV(data []byte) ([]models.UserImportRow, error) {
+	reader := csv.NewReader(utils.SkipBOM(bytes.NewReader(data)))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	index := map[string]int{}
+	for i, col := range header {
+		index[strings.ToLower(strings.TrimSpace(col))] = i
+	}
+	for _, col := range []string{"name", "email"} {
+		if _, ok := index[col]; !ok {
+			return nil, fmt.Errorf("missing required column %q", col)
+		}
+	}
+
+	get := func(record []string, col string) string {
+		if i, ok := index[col]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	rows := []models.UserImportRow{}
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			rows = append(rows, models.UserImportRow{Line: line, Errors: []string{err.Error()}})
+			continue
+		}
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+
+		rows = append(rows, models.UserImportRow{
+			Line:           line,
+			Name:           get(record, "name"),
+			Email:          strings.ToLower(get(record, "email")),
+			Role:           strings.ToLower(get(record, "role")),
+			Department:     get(record, "department"),
+			Site:           get(record, "site"),
+			EmployeeNumber: get(record, "employee_number"),
+		})
+	}
+	return rows, nil
+}
+
+// queueInvite mengantrikan email berisi link untuk mengatur password di dalam transaksi import.
+// Token dibuat worker outbox saat email dikirim (lihat jobs.EnqueueInvite).
+func queueInvite(ctx context.Context, tx pgx.Tx, userID, email, name string) error {
+	appURL := os.Getenv("APP_URL")
+	if appURL == "" {
+		appURL = "http://localhost:3000"
+	}
+	data := map[string]interface{}{
+		"Name":       name,
+		"Link":       appURL + "/set-password?token=" + models.PasswordTokenPlaceholder,
+		"ValidHours": int(jobs.InviteTTL.Hours()),
+	}
+	return jobs.EnqueueInvite(ctx, tx, email, userID, data)
+}
+
+// ImportUsers membuat banyak user sekaligus dari CSV (name, email, role, department, site, employee_number).
+// Semua baris divalidasi dulu; ?dry_run=true hanya mengembalikan laporan. Jika ada baris tidak valid
+// tidak ada user yang dibuat. ?send_invites=true mengantrikan email link untuk mengatur password.
+func ImportUsers(w http.ResponseWriter, r *http.Request) {
+	data, _, err := readImportFile(r)
+	if err != nil {
+		http.Error(w, "Failed to read import file", http.StatusBadRequest)
+		return
+	}
+
+	rows, err := parseUserCSV(data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+	sendInvites := r.URL.Query().Get("send_invites") == "true"
+
+	// Data pembanding untuk validasi
+	departments := map[string]string{}
+	deptRows, err := database.DB.Query(r.Context(), "SELECT id::TEXT, LOWER(name) FROM departments")
+	if err != nil {
+		log.Println("Error fetching departments:", err)
+		http.Error(w, "Failed to fetch departments", http.StatusInternalServerError)
+		return
+	}
+	for deptRows.Next() {
+		var id, name string
+		if err := deptRows.Scan(&id, &name); err != nil {
+			deptRows.Close()
+			log.Println("Error scanning department:", err)
+			http.Error(w, "Error scanning data", http.StatusInternalServerError)
+			return
+		}
+		departments[name] = id
+	}
+	deptRows.Close()
+
+	emails, employeeNumbers := []string{}, []string{}
+	for _, row := range rows {
+		emails = append(emails, row.Email)
+		if row.EmployeeNumber != "" {
+			employeeNumbers = append(employeeNumbers, row.EmployeeNumber)
+		}
+	}
+	existingEmails, existingNumbers := map[string]bool{}, map[string]bool{}
+	existingRows, err := database.DB.Query(r.Context(), `
+        SELECT LOWER(email), COALESCE(employee_number, '') FROM users
+        WHERE LOWER(email) = ANY($1) OR employee_number = ANY($2)`, emails, employeeNumbers)
+	if err != nil {
+		log.Println("Error fetching existing users:", err)
+		http.Error(w, "Failed to validate users", http.StatusInternalServerError)
+		return
+	}
+	for existingRows.Next() {
+		var email, number string
+		if err := existingRows.Scan(&email, &number); err != nil {
+			existingRows.Close()
+			log.Println("Error scanning user:", err)
+			http.Error(w, "Error scanning data", http.StatusInternalServerError)
+			return
+		}
+		existingEmails[email] = true
+		if number != "" {
+			existingNumbers[number] = true
+		}
+	}
+	existingRows.Close()
+
+	report := models.UserImportReport{DryRun: dryRun, Rows: rows}
+	fileEmails, fileNumbers := map[string]int{}, map[string]int{}
+	for i := range report.Rows {
+		row := &report.Rows[i]
+		if row.Role == "" {
+			row.Role = models.RoleEmployee
+		}
+
+		if row.Name == "" {
+			row.Errors = append(row.Errors, "name is required")
+		}
+		if addr, err := mail.ParseAddress(row.Email); err != nil || addr.Address != row.Email {
+			row.Errors = append(row.Errors, "invalid email")
+		} else if existingEmails[row.Email] {
+			row.Errors = append(row.Errors, "email already registered")
+		} else if line, ok := fileEmails[row.Email]; ok {
+			row.Errors = append(row.Errors, fmt.Sprintf("duplicate email, first seen on line %d", line))
+		}
+		fileEmails[row.Email] = row.Line
+
+		if !models.ValidRole(row.Role) {
+			row.Errors = append(row.Errors, "invalid role")
+		}
+		if row.Department != "" {
+			if _, ok := departments[strings.ToLower(row.Department)]; !ok {
+				row.Errors = append(row.Errors, "unknown department")
+			}
+		}
+		if row.EmployeeNumber != "" {
+			if existingNumbers[row.EmployeeNumber] {
+				row.Errors = append(row.Errors, "employee number already used")
+			} else if line, ok := fileNumbers[row.EmployeeNumber]; ok {
+				row.Errors = append(row.Errors, fmt.Sprintf("duplicate employee number, first seen on line %d", line))
+			}
+			fileNumbers[row.EmployeeNumber] = row.Line
+		}
+
+		report.Total++
+		if len(row.Errors) == 0 {
+			report.Valid++
+		} else {
+			report.Invalid++
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if dryRun || report.Invalid > 0 {
+		if report.Invalid > 0 {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+		}
+		json.NewEncoder(w).Encode(report)
+		return
+	}
+
+	// Semua baris valid, buat user dalam satu transaksi
+	tx, err := database.DB.Begin(r.Context())
+	if err != nil {
+		log.Println("Error starting import transaction:", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	for i := range report.Rows {
+		row := &report.Rows[i]
+
+		// Password acak, user mengatur password sendiri lewat link undangan
+		randomPassword, err := utils.GenerateToken()
+		if err == nil {
+			randomPassword, err = utils.HashPassword(randomPassword)
+		}
+		if err != nil {
+			http.Error(w, "Password hashing failed", http.StatusInternalServerError)
+			return
+		}
+
+		var departmentID *string
+		if id, ok := departments[strings.ToLower(row.Department)]; ok {
+			departmentID = &id
+		}
+		var employeeNumber *string
+		if row.EmployeeNumber != "" {
+			employeeNumber = &row.EmployeeNumber
+		}
+
+		query := `
+            INSERT INTO users (name, email, password, role, department_id, site, employee_number, created_at)
+            VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, NOW()) RETURNING id::TEXT`
+		err = tx.QueryRow(r.Context(), query, row.Name, row.Email, randomPassword, row.Role, departmentID, row.Site, employeeNumber).Scan(&row.UserID)
+		if err != nil {
+			log.Println("Error importing user:", err)
+			http.Error(w, fmt.Sprintf("Failed to create user on line %d", row.Line), http.StatusInternalServerError)
+			return
+		}
+
+		// Sama seperti Register, setiap user mendapat attendance awal
+		_, err = tx.Exec(r.Context(), `INSERT INTO attendance (user_id, check_in, check_out, latitude, longitude, status, created_at)
+                    VALUES ($1, NULL, NULL, NULL, NULL, 'not checked-in', NOW())`, row.UserID)
+		if err != nil {
+			log.Println("Failed to create attendance record:", err)
+			http.Error(w, "Failed to create attendance record", http.StatusInternalServerError)
+			return
+		}
+
+		err = jobs.EnqueueWebhookEvent(r.Context(), tx, models.WebhookUserCreated, userWebhookData(row.UserID, row.Name, row.Email, row.Role, "import"))
+		if err != nil {
+			log.Println("Error queueing user webhook:", err)
+			http.Error(w, "Failed to import users", http.StatusInternalServerError)
+			return
+		}
+
+		if sendInvites {
+			if err := queueInvite(r.Context(), tx, row.UserID, row.Email, row.Name); err != nil {
+				log.Println("Error creating invite:", err)
+				http.Error(w, "Failed to create invitation", http.StatusInternalServerError)
+				return
+			}
+			row.InviteQueued = true
+		}
+		report.Created++
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Println("Error committing user import:", err)
+		http.Error(w, "Failed to import users", http.StatusInternalServerError)
+		return
+	}
+
+	createdIDs := make([]string, 0, len(report.Rows))
+	for _, row := range report.Rows {
+		createdIDs = append(createdIDs, row.UserID)
+	}
+	recordAudit(r, models.AuditUsersImported, "user", "", nil, map[string]interface{}{"created": report.Created, "user_ids": createdIDs})
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(report)
+}
+
+// ExportUsers mengekspor semua user yang belum dihapus ke CSV dengan kolom yang sama seperti import
+func ExportUsers(w http.ResponseWriter, r *http.Request) {
+	query := `
+        SELECT u.name, u.email, u.role, COALESCE(d.name, ''), COALESCE(u.site, ''), COALESCE(u.employee_number, '')
+        FROM users u
+        LEFT JOIN departments d ON d.id = u.department_id
+        WHERE u.deleted_at IS NULL
+        ORDER BY u.name ASC`
+
+	rows, err := database.DB.Query(r.Context(), query)
+	if err != nil {
+		log.Println("Error fetching users:", err)
+		http.Error(w, "Failed to fetch users", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="users.csv"`)
+
+	table := utils.NewCSVWriter(w)
+	header := make([]interface{}, len(userCSVColumns))
+	for i, col := range userCSVColumns {
+		header[i] = col
+	}
+	table.WriteRow(header...)
+
+	for rows.Next() {
+		var name, email, role, department, site, employeeNumber string
+		if err := rows.Scan(&name, &email, &role, &department, &site, &employeeNumber); err != nil {
+			log.Println("Error scanning user:", err)
+			return
+		}
+		if err := table.WriteRow(name, email, role, department, site, employeeNumber); err != nil {
+			log.Println("Error writing users CSV:", err)
+			return
+		}
+	}
+	if err := table.Close(); err != nil {
+		log.Println("Error finishing users CSV:", err)
+	}
+}
+
+// SetPassword mengatur password baru memakai token dari email undangan
+func SetPassword(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	if len(data.Password) < 8 {
+		http.Error(w, "Password must be at least 8 characters", http.StatusBadRequest)
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(data.Password)
+	if err != nil {
+		http.Error(w, "Password hashing failed", http.StatusInternalServerError)
+		return
+	}
+
+	tx, err := database.DB.Begin(r.Context())
+	if err != nil {
+		log.Println("Error starting transaction:", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	// Token hanya bisa dipakai sekali
+	var userID string
+	err = tx.QueryRow(r.Context(), `
+        UPDATE password_tokens SET used_at = NOW()
+        WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+        RETURNING user_id::TEXT`, utils.HashToken(data.Token)).Scan(&userID)
+	if err != nil {
+		http.Error(w, "Invalid or expired token", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := tx.Exec(r.Context(), "UPDATE users SET password = $1 WHERE id = $2", hashedPassword, userID); err != nil {
+		log.Println("Error updating password:", err)
+		http.Error(w, "Failed to set password", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Println("Error committing password:", err)
+		http.Error(w, "Failed to set password", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Password has been set"})
+}
@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseUserCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []string // email per baris
+		wantErr string
+	}{
+		{
+			name:  "plain header",
+			input: "name,email,role\nBudi Santoso,Budi@Example.com,Employee\n",
+			want:  []string{"budi@example.com"},
+		},
+		{
+			name:  "excel csv utf-8 with byte order mark",
+			input: "\ufeffname,email,role,department\r\nBudi Santoso,budi@example.com,employee,Engineering\r\nSiti Rahma,siti@example.com,manager,\r\n",
+			want:  []string{"budi@example.com", "siti@example.com"},
+		},
+		{
+			name:  "reordered and padded header",
+			input: " Email , NAME \nbudi@example.com,Budi Santoso\n",
+			want:  []string{"budi@example.com"},
+		},
+		{
+			name:    "missing required column",
+			input:   "\ufeffname,role\nBudi Santoso,employee\n",
+			wantErr: `missing required column "email"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := parseUserCSV([]byte(tt.input))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseUserCSV() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseUserCSV() error = %v", err)
+			}
+			if len(rows) != len(tt.want) {
+				t.Fatalf("got %d rows, want %d: %+v", len(rows), len(tt.want), rows)
+			}
+			for i, email := range tt.want {
+				if rows[i].Email != email || rows[i].Name == "" {
+					t.Errorf("row %d = %+v, want email %q", i, rows[i], email)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package controller
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"absensi/database"
+	"absensi/events"
+	"absensi/jobs"
+	"absensi/models"
+)
+
+// LogVisit mencatat kedatangan karyawan di lokasi customer saat dinas luar
+func LogVisit(w http.ResponseWriter, r *http.Request) {
+	// Ambil user_id dari context dengan aman
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok || userID == "" {
+		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	// User yang dinonaktifkan atau dihapus tidak boleh mencatat kehadiran
+	active, err := isActiveUser(r.Context(), userID)
+	if err != nil {
+		log.Println("Error checking user status:", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if !active {
+		http.Error(w, "Account is deactivated", http.StatusForbidden)
+		return
+	}
+
+	var requestData struct {
+		LocationName string  `json:"location_name"`
+		Latitude     float64 `json:"latitude"`
+		Longitude    float64 `json:"longitude"`
+		Notes        string  `json:"notes"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	requestData.LocationName = strings.TrimSpace(requestData.LocationName)
+	if requestData.LocationName == "" {
+		http.Error(w, "Location name is required", http.StatusBadRequest)
+		return
+	}
+
+	// Kunjungan ditempelkan ke attendance terakhir milik user
+	var attendanceID string
+	err = database.DB.QueryRow(r.Context(), `SELECT id FROM attendance WHERE user_id = $1 ORDER BY created_at DESC LIMIT 1`, userID).Scan(&attendanceID)
+	if err != nil {
+		log.Println("Error fetching attendance ID:", err)
+		http.Error(w, "Attendance record not found", http.StatusNotFound)
+		return
+	}
+
+	var visit models.AttendanceLog
+	query := `
+        INSERT INTO attendance_logs (attendance_id, type, location_name, notes, latitude, longitude, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, NOW())
+        RETURNING id::TEXT, attendance_id::TEXT, type, location_name, notes, latitude, longitude, created_at`
+	err = database.DB.QueryRow(
+		r.Context(), query,
+		attendanceID, models.LogTypeVisit, requestData.LocationName, requestData.Notes, requestData.Latitude, requestData.Longitude,
+	).Scan(&visit.ID, &visit.AttendanceID, &visit.Type, &visit.LocationName, &visit.Notes, &visit.Latitude, &visit.Longitude, &visit.CreatedAt)
+	if err != nil {
+		log.Println("Error inserting visit:", err)
+		http.Error(w, "Failed to log visit", http.StatusInternalServerError)
+		return
+	}
+
+	if err := jobs.EnqueueWebhookEvent(r.Context(), database.DB, models.WebhookAttendanceVisit, attendanceWebhookData(userID, visit)); err != nil {
+		log.Println("Error queueing visit webhook:", err)
+	}
+
+	// Kirim event ke live board
+	publishAttendanceEvent(r.Context(), events.TypeVisit, userID, visit.Latitude, visit.Longitude)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(visit)
+}
+
+// GetVisitRoute mengembalikan rute perjalanan user dalam satu hari beserta jarak antar titik
+func GetVisitRoute(w http.ResponseWriter, r *http.Request) {
+	// Ambil user_id dari context dengan aman
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok || userID == "" {
+		http.Error(w, "User ID is missing", http.StatusUnauthorized)
+		return
+	}
+
+	// Tanggal dalam format YYYY-MM-DD, default hari ini
+	start, err := parseDay(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	end := start.AddDate(0, 0, 1)
+
+	query := `
+        SELECT al.id::TEXT, COALESCE(al.type, ''), COALESCE(al.location_name, ''), COALESCE(al.notes, ''),
+               COALESCE(al.latitude, 0), COALESCE(al.longitude, 0), al.created_at
+        FROM attendance_logs al
+        JOIN attendance a ON al.attendance_id = a.id
+        WHERE a.user_id = $1 AND al.created_at >= $2 AND al.created_at < $3
+        ORDER BY al.created_at ASC
+    `
+
+	rows, err := database.DB.Query(r.Context(), query, userID, start, end)
+	if err != nil {
+		log.Println("Failed to fetch visit route:", err)
+		http.Error(w, "Failed to fetch visit route", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	route := models.VisitRoute{
+		UserID: userID,
+		Date:   start.Format("2006-01-02"),
+		Stops:  []models.VisitStop{},
+	}
+
+	for rows.Next() {
+		var stop models.VisitStop
+		err := rows.Scan(&stop.ID, &stop.Type, &stop.LocationName, &stop.Notes, &stop.Latitude, &stop.Longitude, &stop.ArrivedAt)
+		if err != nil {
+			log.Println("Error scanning visit data:", err)
+			http.Error(w, "Error scanning visit data", http.StatusInternalServerError)
+			return
+		}
+
+		// Hitung jarak dari titik sebelumnya
+		if n := len(route.Stops); n > 0 {
+			prev := route.Stops[n-1]
+			stop.DistanceFromPrev = HaversineDistance(prev.Latitude, prev.Longitude, stop.Latitude, stop.Longitude)
+			route.TotalDistance += stop.DistanceFromPrev
+		}
+		route.Stops = append(route.Stops, stop)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Println("Error iterating rows:", err)
+		http.Error(w, "Error processing visit route", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(route)
+}
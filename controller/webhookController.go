@@ -0,0 +1,367 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"absensi/database"
+	"absensi/jobs"
+	"absensi/models"
+	"absensi/utils"
+
+	"github.com/gorilla/mux"
+)
+
+const webhookColumns = `id::TEXT, url, COALESCE(description, ''), event_types, active, created_at`
+
+const webhookDeliveryColumns = `id::TEXT, endpoint_id::TEXT, event_type, payload::TEXT, status, attempts, next_attempt_at,
+        response_status, COALESCE(response_body, ''), COALESCE(last_error, ''), created_at, delivered_at`
+
+func scanWebhook(row rowScanner) (models.WebhookEndpoint, error) {
+	var hook models.WebhookEndpoint
+	err := row.Scan(&hook.ID, &hook.URL, &hook.Description, &hook.EventTypes, &hook.Active, &hook.CreatedAt)
+	return hook, err
+}
+
+func scanWebhookDelivery(row rowScanner) (models.WebhookDelivery, error) {
+	var d models.WebhookDelivery
+	var payload string
+	err := row.Scan(&d.ID, &d.EndpointID, &d.EventType, &payload, &d.Status, &d.Attempts, &d.NextAttemptAt,
+		&d.ResponseStatus, &d.ResponseBody, &d.LastError, &d.CreatedAt, &d.DeliveredAt)
+	d.Payload = json.RawMessage(payload)
+	return d, err
+}
+
+// attendanceWebhookData adalah data event webhook untuk satu log kehadiran
+func attendanceWebhookData(userID string, entry models.AttendanceLog) map[string]interface{} {
+	return map[string]interface{}{
+		"id":            entry.ID,
+		"user_id":       userID,
+		"attendance_id": entry.AttendanceID,
+		"type":          entry.Type,
+		"location_name": entry.LocationName,
+		"notes":         entry.Notes,
+		"latitude":      entry.Latitude,
+		"longitude":     entry.Longitude,
+		"created_at":    entry.CreatedAt,
+	}
+}
+
+// userWebhookData adalah data event user.created, source menandakan asal user (register atau import)
+func userWebhookData(userID, name, email, role, source string) map[string]string {
+	return map[string]string{"id": userID, "name": name, "email": email, "role": role, "source": source}
+}
+
+// validateWebhookInput memeriksa URL (http/https) dan jenis event yang dipilih
+func validateWebhookInput(rawURL string, eventTypes []string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("URL must be an absolute http or https URL")
+	}
+	// Host berupa nama dicek lagi saat dikirim, setelah DNS di-resolve (lihat jobs/webhooks.go)
+	host := strings.ToLower(u.Hostname())
+	ip := net.ParseIP(host)
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		ip = net.IPv4(127, 0, 0, 1)
+	}
+	if ip != nil && !utils.WebhookTargetAllowed(ip) {
+		return fmt.Errorf("URL must point to a public address")
+	}
+	if len(eventTypes) == 0 {
+		return fmt.Errorf("At least one event type is required")
+	}
+	for _, eventType := range eventTypes {
+		known := false
+		for _, t := range models.WebhookEventTypes {
+			if t == eventType {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("Unknown event type: %s", eventType)
+		}
+	}
+	return nil
+}
+
+// newWebhookSecret membuat secret untuk tanda tangan HMAC
+func newWebhookSecret() (string, error) {
+	token, err := utils.GenerateToken()
+	if err != nil {
+		return "", err
+	}
+	return "whsec_" + token, nil
+}
+
+// GetWebhooks mengembalikan semua webhook terdaftar beserta daftar event yang tersedia
+func GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	rows, err := database.DB.Query(r.Context(), "SELECT "+webhookColumns+" FROM webhook_endpoints ORDER BY created_at ASC")
+	if err != nil {
+		log.Println("Error fetching webhooks:", err)
+		http.Error(w, "Failed to fetch webhooks", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	hooks := []models.WebhookEndpoint{}
+	for rows.Next() {
+		hook, err := scanWebhook(rows)
+		if err != nil {
+			log.Println("Error scanning webhook:", err)
+			http.Error(w, "Error scanning data", http.StatusInternalServerError)
+			return
+		}
+		hooks = append(hooks, hook)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data":        hooks,
+		"event_types": models.WebhookEventTypes,
+	})
+}
+
+// CreateWebhook mendaftarkan endpoint baru. Secret hanya ditampilkan sekali di response ini.
+func CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		URL         string   `json:"url"`
+		Description string   `json:"description"`
+		EventTypes  []string `json:"event_types"`
+		Active      *bool    `json:"active"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	data.URL = strings.TrimSpace(data.URL)
+	if err := validateWebhookInput(data.URL, data.EventTypes); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	active := data.Active == nil || *data.Active
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		http.Error(w, "Failed to generate secret", http.StatusInternalServerError)
+		return
+	}
+
+	query := `
+        INSERT INTO webhook_endpoints (url, description, event_types, active, secret, created_at)
+        VALUES ($1, NULLIF($2, ''), $3, $4, $5, NOW())
+        RETURNING ` + webhookColumns
+	hook, err := scanWebhook(database.DB.QueryRow(r.Context(), query, data.URL, data.Description, data.EventTypes, active, secret))
+	if err != nil {
+		log.Println("Error creating webhook:", err)
+		http.Error(w, "Failed to create webhook", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, models.AuditWebhookCreated, "webhook", hook.ID, nil, hook)
+	hook.Secret = secret
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(hook)
+}
+
+// UpdateWebhook mengubah URL, deskripsi, event atau status aktif webhook
+func UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	hookID := mux.Vars(r)["id"]
+
+	before, err := scanWebhook(database.DB.QueryRow(r.Context(), "SELECT "+webhookColumns+" FROM webhook_endpoints WHERE id = $1", hookID))
+	if err != nil {
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+		return
+	}
+
+	var data struct {
+		URL         *string   `json:"url"`
+		Description *string   `json:"description"`
+		EventTypes  *[]string `json:"event_types"`
+		Active      *bool     `json:"active"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	after := before
+	if data.URL != nil {
+		after.URL = strings.TrimSpace(*data.URL)
+	}
+	if data.Description != nil {
+		after.Description = *data.Description
+	}
+	if data.EventTypes != nil {
+		after.EventTypes = *data.EventTypes
+	}
+	if data.Active != nil {
+		after.Active = *data.Active
+	}
+	if err := validateWebhookInput(after.URL, after.EventTypes); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	query := `
+        UPDATE webhook_endpoints SET url = $1, description = NULLIF($2, ''), event_types = $3, active = $4
+        WHERE id = $5
+        RETURNING ` + webhookColumns
+	hook, err := scanWebhook(database.DB.QueryRow(r.Context(), query, after.URL, after.Description, after.EventTypes, after.Active, hookID))
+	if err != nil {
+		log.Println("Error updating webhook:", err)
+		http.Error(w, "Failed to update webhook", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, models.AuditWebhookUpdated, "webhook", hookID, before, hook)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hook)
+}
+
+// DeleteWebhook menghapus webhook beserta log pengirimannya
+func DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	hookID := mux.Vars(r)["id"]
+
+	before, err := scanWebhook(database.DB.QueryRow(r.Context(), "DELETE FROM webhook_endpoints WHERE id = $1 RETURNING "+webhookColumns, hookID))
+	if err != nil {
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+		return
+	}
+
+	recordAudit(r, models.AuditWebhookDeleted, "webhook", hookID, before, nil)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Webhook deleted successfully"})
+}
+
+// RotateWebhookSecret mengganti secret webhook, secret baru hanya ditampilkan sekali
+func RotateWebhookSecret(w http.ResponseWriter, r *http.Request) {
+	hookID := mux.Vars(r)["id"]
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		http.Error(w, "Failed to generate secret", http.StatusInternalServerError)
+		return
+	}
+
+	hook, err := scanWebhook(database.DB.QueryRow(r.Context(),
+		"UPDATE webhook_endpoints SET secret = $1 WHERE id = $2 RETURNING "+webhookColumns, secret, hookID))
+	if err != nil {
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+		return
+	}
+
+	recordAudit(r, models.AuditWebhookRotated, "webhook", hookID, nil, nil)
+	hook.Secret = secret
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hook)
+}
+
+// GetWebhookDeliveries mengembalikan log pengiriman satu webhook, bisa difilter dengan ?status=
+func GetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	hookID := mux.Vars(r)["id"]
+	status := r.URL.Query().Get("status")
+
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	pageSize, err := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if err != nil || pageSize < 1 || pageSize > 200 {
+		pageSize = 50
+	}
+
+	where := `WHERE endpoint_id = $1 AND ($2 = '' OR status = $2)`
+	result := models.WebhookDeliveryPage{Data: []models.WebhookDelivery{}, Page: page, PageSize: pageSize}
+	if err := database.DB.QueryRow(r.Context(), "SELECT COUNT(*) FROM webhook_deliveries "+where, hookID, status).Scan(&result.Total); err != nil {
+		log.Println("Error counting webhook deliveries:", err)
+		http.Error(w, "Failed to fetch deliveries", http.StatusInternalServerError)
+		return
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM webhook_deliveries %s ORDER BY created_at DESC LIMIT %d OFFSET %d",
+		webhookDeliveryColumns, where, pageSize, (page-1)*pageSize)
+	rows, err := database.DB.Query(r.Context(), query, hookID, status)
+	if err != nil {
+		log.Println("Error fetching webhook deliveries:", err)
+		http.Error(w, "Failed to fetch deliveries", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			log.Println("Error scanning webhook delivery:", err)
+			http.Error(w, "Error scanning data", http.StatusInternalServerError)
+			return
+		}
+		result.Data = append(result.Data, d)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// TestWebhook langsung mengirim event webhook.test dan mengembalikan hasil pengirimannya
+func TestWebhook(w http.ResponseWriter, r *http.Request) {
+	hookID := mux.Vars(r)["id"]
+
+	var active bool
+	if err := database.DB.QueryRow(r.Context(), "SELECT active FROM webhook_endpoints WHERE id = $1", hookID).Scan(&active); err != nil {
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+		return
+	}
+	if !active {
+		http.Error(w, "Webhook is disabled", http.StatusConflict)
+		return
+	}
+
+	deliveryID, err := jobs.SendTestWebhook(r.Context(), hookID)
+	if err != nil {
+		log.Println("Error sending test webhook:", err)
+		http.Error(w, "Failed to send test webhook", http.StatusInternalServerError)
+		return
+	}
+
+	d, err := scanWebhookDelivery(database.DB.QueryRow(r.Context(), "SELECT "+webhookDeliveryColumns+" FROM webhook_deliveries WHERE id = $1", deliveryID))
+	if err != nil {
+		log.Println("Error fetching test delivery:", err)
+		http.Error(w, "Failed to fetch delivery", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(d)
+}
+
+// RetryWebhookDelivery mengantrikan ulang pengiriman yang gagal untuk segera dikirim
+func RetryWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	deliveryID := mux.Vars(r)["id"]
+
+	query := `
+        UPDATE webhook_deliveries SET status = $1, attempts = 0, next_attempt_at = NOW()
+        WHERE id = $2 AND status = $3
+        RETURNING ` + webhookDeliveryColumns
+	d, err := scanWebhookDelivery(database.DB.QueryRow(r.Context(), query, models.WebhookDeliveryPending, deliveryID, models.WebhookDeliveryFailed))
+	if err != nil {
+		http.Error(w, "Failed webhook delivery not found", http.StatusNotFound)
+		return
+	}
+
+	recordAudit(r, models.AuditWebhookRedriven, "webhook_delivery", deliveryID, nil, nil)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(d)
+}
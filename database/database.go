@@ -2,13 +2,96 @@ package database
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-var DB *pgx.Conn
+// DB adalah pool koneksi yang dipakai bersama oleh semua request dan job background.
+// Setiap query sebaiknya memakai context request agar dibatalkan saat client terputus.
+var DB *pgxpool.Pool
+
+// execModes adalah nilai yang diterima DB_EXEC_MODE
+var execModes = map[string]pgx.QueryExecMode{
+	"cache_statement": pgx.QueryExecModeCacheStatement,
+	"cache_describe":  pgx.QueryExecModeCacheDescribe,
+	"describe_exec":   pgx.QueryExecModeDescribeExec,
+	"exec":            pgx.QueryExecModeExec,
+	"simple_protocol": pgx.QueryExecModeSimpleProtocol,
+}
+
+// PoolConfig menyusun konfigurasi pool dari connection string dan env berikut (semuanya opsional,
+// tanpa env dipakai nilai dari connection string atau default pgx):
+//   - DB_MAX_CONNS, DB_MIN_CONNS: ukuran pool
+//   - DB_MAX_CONN_LIFETIME, DB_MAX_CONN_IDLE_TIME: umur koneksi (durasi Go, mis. 1h, 30m)
+//   - DB_HEALTH_CHECK_PERIOD: interval pemeriksaan koneksi idle (durasi Go)
+//   - DB_CONNECT_TIMEOUT: batas waktu membuka koneksi baru (durasi Go)
+//   - DB_STATEMENT_TIMEOUT: statement_timeout Postgres untuk setiap koneksi (durasi Go)
+//   - DB_STATEMENT_CACHE_CAPACITY: jumlah prepared statement yang di-cache per koneksi
+//   - DB_EXEC_MODE: cache_statement (default), cache_describe, describe_exec, exec atau
+//     simple_protocol (untuk pgbouncer/Supavisor mode transaction yang tidak mendukung prepared statement)
+func PoolConfig(connStr string) (*pgxpool.Config, error) {
+	cfg, err := pgxpool.ParseConfig(connStr)
+	if err != nil {
+		return nil, err
+	}
+
+	if n, err := strconv.Atoi(os.Getenv("DB_MAX_CONNS")); err == nil && n > 0 {
+		cfg.MaxConns = int32(n)
+	}
+	if n, err := strconv.Atoi(os.Getenv("DB_MIN_CONNS")); err == nil && n >= 0 {
+		cfg.MinConns = int32(n)
+	}
+	if cfg.MinConns > cfg.MaxConns {
+		return nil, fmt.Errorf("DB_MIN_CONNS (%d) must not exceed DB_MAX_CONNS (%d)", cfg.MinConns, cfg.MaxConns)
+	}
+
+	durations := []struct {
+		env    string
+		target *time.Duration
+	}{
+		{"DB_MAX_CONN_LIFETIME", &cfg.MaxConnLifetime},
+		{"DB_MAX_CONN_IDLE_TIME", &cfg.MaxConnIdleTime},
+		{"DB_HEALTH_CHECK_PERIOD", &cfg.HealthCheckPeriod},
+		{"DB_CONNECT_TIMEOUT", &cfg.ConnConfig.ConnectTimeout},
+	}
+	for _, d := range durations {
+		value := os.Getenv(d.env)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("invalid %s: %q", d.env, value)
+		}
+		*d.target = parsed
+	}
+
+	if value := os.Getenv("DB_STATEMENT_TIMEOUT"); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout < 0 {
+			return nil, fmt.Errorf("invalid DB_STATEMENT_TIMEOUT: %q", value)
+		}
+		cfg.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(timeout.Milliseconds(), 10)
+	}
+	if n, err := strconv.Atoi(os.Getenv("DB_STATEMENT_CACHE_CAPACITY")); err == nil && n >= 0 {
+		cfg.ConnConfig.StatementCacheCapacity = n
+	}
+	if value := strings.ToLower(os.Getenv("DB_EXEC_MODE")); value != "" {
+		mode, ok := execModes[value]
+		if !ok {
+			return nil, fmt.Errorf("invalid DB_EXEC_MODE: %q", value)
+		}
+		cfg.ConnConfig.DefaultQueryExecMode = mode
+	}
+	return cfg, nil
+}
 
 func InitDB() {
 	connStr := os.Getenv("SUPABASE_DB_URL") // Gunakan connection string dari .env
@@ -16,10 +99,34 @@ func InitDB() {
 		log.Fatal("SUPABASE_DB_URL is not set in environment variables")
 	}
 
-	var err error
-	DB, err = pgx.Connect(context.Background(), connStr)
+	cfg, err := PoolConfig(connStr)
+	if err != nil {
+		log.Fatal("Invalid database configuration: ", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	DB, err = pgxpool.NewWithConfig(ctx, cfg)
 	if err != nil {
+		log.Fatal("Failed to create database pool:", err)
+	}
+	if err := DB.Ping(ctx); err != nil {
 		log.Fatal("Failed to connect to database:", err)
 	}
-	log.Println("Connected to Supabase successfully")
+	log.Printf("Connected to Supabase successfully (max %d connections)", cfg.MaxConns)
+}
+
+// Close menutup semua koneksi di pool
+func Close() {
+	if DB != nil {
+		DB.Close()
+	}
+}
+
+// Ping memastikan database bisa dijangkau dalam batas waktu 5 detik
+func Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	return DB.Ping(ctx)
 }
@@ -0,0 +1,273 @@
+package database
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLockKey adalah kunci advisory lock Postgres agar hanya satu instance yang menjalankan migrasi
+const migrationLockKey = 7301050
+
+// Migration adalah satu versi skema, dibaca dari migrations/<versi>_<nama>.up.sql dan .down.sql
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus adalah status satu migrasi di database
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
+
+// LoadMigrations membaca semua migrasi yang di-embed, terurut berdasarkan versi
+func LoadMigrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		file := entry.Name()
+		var direction string
+		switch {
+		case strings.HasSuffix(file, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(file, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		base := strings.TrimSuffix(file, "."+direction+".sql")
+		versionPart, name, ok := strings.Cut(base, "_")
+		version, err := strconv.Atoi(versionPart)
+		if !ok || err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration file name %q, expected <version>_<name>.up.sql", file)
+		}
+
+		body, err := migrationFiles.ReadFile("migrations/" + file)
+		if err != nil {
+			return nil, err
+		}
+
+		m, exists := byVersion[version]
+		if !exists {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, m.Name, name)
+		}
+		if direction == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// ensureMigrationTable membuat tabel schema_migrations jika belum ada. Dijalankan di bawah
+// advisory lock karena CREATE TABLE IF NOT EXISTS bisa bentrok jika dua instance start bersamaan.
+func ensureMigrationTable(ctx context.Context) error {
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", migrationLockKey); err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `
+        CREATE TABLE IF NOT EXISTS schema_migrations (
+            version    BIGINT PRIMARY KEY,
+            name       TEXT NOT NULL,
+            applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// lockedStep menjalankan fn dalam satu transaksi yang memegang advisory lock migrasi.
+// Lock dilepas otomatis saat transaksi selesai, sehingga tetap aman di belakang connection pooler
+// mode transaction. Instance lain menunggu lalu membaca ulang versi yang sudah diterapkan.
+func lockedStep(ctx context.Context, fn func(tx pgx.Tx, applied map[int]bool) error) error {
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", migrationLockKey); err != nil {
+		return err
+	}
+
+	rows, err := tx.Query(ctx, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return err
+	}
+	applied := map[int]bool{}
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			rows.Close()
+			return err
+		}
+		applied[version] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if err := fn(tx, applied); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// MigrateUp menerapkan semua migrasi yang belum dijalankan, masing-masing dalam transaksinya sendiri,
+// dan mengembalikan migrasi yang baru diterapkan
+func MigrateUp(ctx context.Context) ([]Migration, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureMigrationTable(ctx); err != nil {
+		return nil, err
+	}
+
+	done := []Migration{}
+	for _, m := range migrations {
+		ran := false
+		err := lockedStep(ctx, func(tx pgx.Tx, applied map[int]bool) error {
+			if applied[m.Version] {
+				return nil
+			}
+			if _, err := tx.Exec(ctx, m.Up); err != nil {
+				return err
+			}
+			if _, err := tx.Exec(ctx, "INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, NOW())", m.Version, m.Name); err != nil {
+				return err
+			}
+			ran = true
+			return nil
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %d_%s: %w", m.Version, m.Name, err)
+		}
+		if ran {
+			log.Printf("Applied migration %d_%s", m.Version, m.Name)
+			done = append(done, m)
+		}
+	}
+	return done, nil
+}
+
+// MigrateDown membatalkan steps migrasi terakhir yang sudah diterapkan, dari versi tertinggi
+func MigrateDown(ctx context.Context, steps int) ([]Migration, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureMigrationTable(ctx); err != nil {
+		return nil, err
+	}
+
+	done := []Migration{}
+	for i := len(migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		m := migrations[i]
+		ran := false
+		err := lockedStep(ctx, func(tx pgx.Tx, applied map[int]bool) error {
+			if !applied[m.Version] {
+				return nil
+			}
+			if m.Down == "" {
+				return fmt.Errorf("no down migration")
+			}
+			if _, err := tx.Exec(ctx, m.Down); err != nil {
+				return err
+			}
+			if _, err := tx.Exec(ctx, "DELETE FROM schema_migrations WHERE version = $1", m.Version); err != nil {
+				return err
+			}
+			ran = true
+			return nil
+		})
+		if err != nil {
+			return done, fmt.Errorf("rollback %d_%s: %w", m.Version, m.Name, err)
+		}
+		if ran {
+			log.Printf("Rolled back migration %d_%s", m.Version, m.Name)
+			done = append(done, m)
+		}
+	}
+	return done, nil
+}
+
+// GetMigrationStatus mengembalikan semua migrasi beserta waktu diterapkan (nil jika belum)
+func GetMigrationStatus(ctx context.Context) ([]MigrationStatus, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureMigrationTable(ctx); err != nil {
+		return nil, err
+	}
+
+	appliedAt := map[int]time.Time{}
+	rows, err := DB.Query(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var version int
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+		appliedAt[version] = at
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	status := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		s := MigrationStatus{Version: m.Version, Name: m.Name}
+		if at, ok := appliedAt[m.Version]; ok {
+			s.AppliedAt = &at
+		}
+		status = append(status, s)
+	}
+	return status, nil
+}
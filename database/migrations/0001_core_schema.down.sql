@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS attendance_logs;
+DROP TABLE IF EXISTS attendance;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,33 @@
+-- Skema awal yang sebelumnya hanya ada di Supabase. IF NOT EXISTS agar migrasi ini
+-- aman dijalankan pada database lama yang tabelnya sudah dibuat manual.
+CREATE TABLE IF NOT EXISTS users (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name       TEXT NOT NULL,
+    email      TEXT NOT NULL,
+    password   TEXT NOT NULL,
+    role       TEXT NOT NULL DEFAULT 'employee',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_key ON users (email);
+
+CREATE TABLE IF NOT EXISTS attendance (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id    UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    check_in   TIMESTAMPTZ,
+    check_out  TIMESTAMPTZ,
+    latitude   DOUBLE PRECISION,
+    longitude  DOUBLE PRECISION,
+    status     TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS attendance_user_id_created_at_idx ON attendance (user_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS attendance_logs (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    attendance_id UUID NOT NULL REFERENCES attendance (id) ON DELETE CASCADE,
+    latitude      DOUBLE PRECISION,
+    longitude     DOUBLE PRECISION,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS attendance_logs_attendance_id_created_at_idx ON attendance_logs (attendance_id, created_at);
+CREATE INDEX IF NOT EXISTS attendance_logs_created_at_idx ON attendance_logs (created_at);
@@ -0,0 +1,23 @@
+DROP TABLE IF EXISTS password_tokens;
+DROP TABLE IF EXISTS fingerprint_mappings;
+
+ALTER TABLE attendance_logs
+    DROP COLUMN IF EXISTS source,
+    DROP COLUMN IF EXISTS notes,
+    DROP COLUMN IF EXISTS location_name,
+    DROP COLUMN IF EXISTS type;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS purged_at,
+    DROP COLUMN IF EXISTS deleted_at,
+    DROP COLUMN IF EXISTS avatar_url,
+    DROP COLUMN IF EXISTS phone,
+    DROP COLUMN IF EXISTS status,
+    DROP COLUMN IF EXISTS employee_number,
+    DROP COLUMN IF EXISTS site,
+    DROP COLUMN IF EXISTS manager_id,
+    DROP COLUMN IF EXISTS team_id,
+    DROP COLUMN IF EXISTS department_id;
+
+DROP TABLE IF EXISTS teams;
+DROP TABLE IF EXISTS departments;
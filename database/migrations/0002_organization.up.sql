@@ -0,0 +1,56 @@
+CREATE TABLE IF NOT EXISTS departments (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name       TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS teams (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    department_id UUID NOT NULL REFERENCES departments (id) ON DELETE CASCADE,
+    name          TEXT NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS teams_department_id_idx ON teams (department_id);
+
+-- User dihapus secara soft delete (deleted_at), purged_at menandai data pribadi sudah dianonimkan
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS department_id   UUID REFERENCES departments (id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS team_id         UUID REFERENCES teams (id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS manager_id      UUID REFERENCES users (id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS site            TEXT,
+    ADD COLUMN IF NOT EXISTS employee_number TEXT,
+    ADD COLUMN IF NOT EXISTS status          TEXT NOT NULL DEFAULT 'active',
+    ADD COLUMN IF NOT EXISTS phone           TEXT,
+    ADD COLUMN IF NOT EXISTS avatar_url      TEXT,
+    ADD COLUMN IF NOT EXISTS deleted_at      TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS purged_at       TIMESTAMPTZ;
+CREATE UNIQUE INDEX IF NOT EXISTS users_employee_number_key ON users (employee_number);
+CREATE INDEX IF NOT EXISTS users_manager_id_idx ON users (manager_id);
+CREATE INDEX IF NOT EXISTS users_department_id_idx ON users (department_id);
+
+-- Jenis log (check_in, check_out, visit), kunjungan dinas luar dan punch dari mesin fingerprint.
+-- type boleh NULL karena log lama dibuat sebelum kolom ini ada.
+ALTER TABLE attendance_logs
+    ADD COLUMN IF NOT EXISTS type          TEXT,
+    ADD COLUMN IF NOT EXISTS location_name TEXT,
+    ADD COLUMN IF NOT EXISTS notes         TEXT,
+    ADD COLUMN IF NOT EXISTS source        TEXT NOT NULL DEFAULT 'app';
+
+CREATE TABLE IF NOT EXISTS fingerprint_mappings (
+    device_id      TEXT NOT NULL,
+    device_user_id TEXT NOT NULL,
+    user_id        UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (device_id, device_user_id)
+);
+CREATE INDEX IF NOT EXISTS fingerprint_mappings_user_id_idx ON fingerprint_mappings (user_id);
+
+-- Token undangan / reset password, hanya hash yang disimpan
+CREATE TABLE IF NOT EXISTS password_tokens (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id    UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    token_hash TEXT NOT NULL UNIQUE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at    TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
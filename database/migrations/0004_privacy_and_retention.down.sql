@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS retention_runs;
+ALTER TABLE attendance DROP COLUMN IF EXISTS coarsened_at;
+ALTER TABLE attendance_logs DROP COLUMN IF EXISTS coarsened_at;
+DROP TABLE IF EXISTS erasure_requests;
@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS erasure_requests (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id     UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    reason      TEXT,
+    status      TEXT NOT NULL DEFAULT 'pending',
+    note        TEXT,
+    reviewed_by UUID REFERENCES users (id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS erasure_requests_user_id_status_idx ON erasure_requests (user_id, status);
+
+-- coarsened_at menandai koordinat yang sudah dibulatkan job retensi
+ALTER TABLE attendance_logs ADD COLUMN IF NOT EXISTS coarsened_at TIMESTAMPTZ;
+ALTER TABLE attendance ADD COLUMN IF NOT EXISTS coarsened_at TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS retention_runs (
+    id                   UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    trigger              TEXT NOT NULL,
+    coarsen_before       TIMESTAMPTZ,
+    delete_before        TIMESTAMPTZ,
+    coarsen_decimals     INT NOT NULL,
+    logs_coarsened       BIGINT NOT NULL DEFAULT 0,
+    attendance_coarsened BIGINT NOT NULL DEFAULT 0,
+    logs_cleared         BIGINT NOT NULL DEFAULT 0,
+    attendance_cleared   BIGINT NOT NULL DEFAULT 0,
+    started_at           TIMESTAMPTZ NOT NULL,
+    finished_at          TIMESTAMPTZ
+);
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS device_tokens;
+DROP TABLE IF EXISTS reminder_log;
+DROP TABLE IF EXISTS notification_templates;
+DROP TABLE IF EXISTS notification_outbox;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS notification_preferences,
+    DROP COLUMN IF EXISTS language;
@@ -0,0 +1,54 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS language                 TEXT NOT NULL DEFAULT 'id',
+    ADD COLUMN IF NOT EXISTS notification_preferences JSONB;
+
+-- Outbox: notifikasi ditulis dalam transaksi yang sama dengan datanya, lalu dikirim worker
+CREATE TABLE IF NOT EXISTS notification_outbox (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    channel         TEXT NOT NULL DEFAULT 'email',
+    recipient       TEXT NOT NULL,
+    subject         TEXT NOT NULL,
+    body            TEXT NOT NULL,
+    html_body       TEXT,
+    status          TEXT NOT NULL DEFAULT 'pending',
+    attempts        INT NOT NULL DEFAULT 0,
+    max_attempts    INT NOT NULL DEFAULT 8,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_error      TEXT,
+    locked_at       TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    sent_at         TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS notification_outbox_status_next_attempt_idx ON notification_outbox (status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS notification_outbox_created_at_idx ON notification_outbox (created_at DESC);
+
+-- Template yang diubah admin, template bawaan ada di kode (notify/templates.go)
+CREATE TABLE IF NOT EXISTS notification_templates (
+    key        TEXT NOT NULL,
+    language   TEXT NOT NULL,
+    subject    TEXT NOT NULL,
+    text_body  TEXT NOT NULL,
+    html_body  TEXT,
+    updated_by UUID REFERENCES users (id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (key, language)
+);
+
+-- Pengingat dan ringkasan yang sudah dikirim, satu per user per jenis per hari
+CREATE TABLE IF NOT EXISTS reminder_log (
+    user_id    UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    kind       TEXT NOT NULL,
+    day        DATE NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, kind, day)
+);
+
+CREATE TABLE IF NOT EXISTS device_tokens (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id      UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    token        TEXT NOT NULL UNIQUE,
+    platform     TEXT NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS device_tokens_user_id_idx ON device_tokens (user_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS erasure_requests_status_scheduled_for_idx;
+ALTER TABLE erasure_requests DROP COLUMN IF EXISTS scheduled_for;
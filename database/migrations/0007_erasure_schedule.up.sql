@@ -0,0 +1,3 @@
+-- Penghapusan data yang disetujui dijalankan setelah masa retensi wajib (ERASURE_RETENTION_DAYS)
+ALTER TABLE erasure_requests ADD COLUMN IF NOT EXISTS scheduled_for TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS erasure_requests_status_scheduled_for_idx ON erasure_requests (status, scheduled_for);
@@ -0,0 +1,3 @@
+-- Token set password dibuat worker saat pesan dikirim, sehingga token asli tidak pernah disimpan di outbox
+ALTER TABLE notification_outbox
+    ADD COLUMN IF NOT EXISTS password_token_user_id UUID REFERENCES users (id) ON DELETE CASCADE;
@@ -0,0 +1,101 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Jenis event kehadiran
+const (
+	TypeCheckIn    = "attendance.checked_in"
+	TypeCheckOut   = "attendance.checked_out"
+	TypeBreakStart = "attendance.break_started"
+	TypeBreakEnd   = "attendance.break_ended"
+	TypeVisit      = "attendance.visit"
+)
+
+// Event adalah satu kejadian yang dikirim ke subscriber
+type Event struct {
+	ID           int64     `json:"id"`
+	Type         string    `json:"type"`
+	UserID       string    `json:"user_id"`
+	UserName     string    `json:"user_name"`
+	DepartmentID string    `json:"department_id,omitempty"`
+	Site         string    `json:"site,omitempty"`
+	Latitude     float64   `json:"latitude"`
+	Longitude    float64   `json:"longitude"`
+	OccurredAt   time.Time `json:"occurred_at"`
+}
+
+// historySize adalah jumlah event terakhir yang disimpan untuk reconnect lewat Last-Event-ID
+const historySize = 512
+
+// Bus adalah event bus in-memory sederhana dengan riwayat terbatas
+type Bus struct {
+	mu          sync.Mutex
+	nextID      int64
+	history     []Event
+	subscribers map[chan Event]struct{}
+}
+
+// NewBus membuat event bus baru
+func NewBus() *Bus {
+	return &Bus{subscribers: make(map[chan Event]struct{})}
+}
+
+// Default adalah bus yang dipakai oleh controller
+var Default = NewBus()
+
+// Publish memberi ID pada event lalu mengirimkannya ke semua subscriber.
+// Subscriber yang lambat (buffer penuh) akan melewatkan event, tidak memblokir publisher.
+func (b *Bus) Publish(e Event) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	e.ID = b.nextID
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = time.Now()
+	}
+
+	b.history = append(b.history, e)
+	if len(b.history) > historySize {
+		b.history = b.history[len(b.history)-historySize:]
+	}
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+	return e
+}
+
+// Subscribe mendaftarkan subscriber baru. Event dengan ID > lastID yang masih ada
+// di riwayat dikembalikan sebagai replay. Panggil fungsi cancel setelah selesai.
+func (b *Bus) Subscribe(lastID int64) (replay []Event, ch <-chan Event, cancel func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if lastID > 0 {
+		for _, e := range b.history {
+			if e.ID > lastID {
+				replay = append(replay, e)
+			}
+		}
+	}
+
+	c := make(chan Event, 64)
+	b.subscribers[c] = struct{}{}
+
+	cancel = func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subscribers[c]; ok {
+			delete(b.subscribers, c)
+			close(c)
+		}
+	}
+	return replay, c, cancel
+}
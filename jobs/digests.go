@@ -0,0 +1,202 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"absensi/models"
+	"absensi/notify"
+	"absensi/utils"
+)
+
+// DigestBuilder menyusun ringkasan tim seorang manager untuk periode [from, to)
+type DigestBuilder func(ctx context.Context, managerID, period string, from, to time.Time) (models.ManagerDigest, error)
+
+// SummaryBuilder menyusun rekap kehadiran satu user untuk periode [from, to)
+type SummaryBuilder func(ctx context.Context, userID string, from, to time.Time) (models.MonthlyRecap, error)
+
+// digestTemplateData mengubah ringkasan menjadi data template manager_digest
+func digestTemplateData(name string, digest models.ManagerDigest) map[string]interface{} {
+	periodLabel := formatPeriod(digest.From, digest.To)
+
+	late := []map[string]interface{}{}
+	for _, day := range digest.Late {
+		late = append(late, map[string]interface{}{"Name": day.Name, "Date": day.Date.Format("02-01-2006"), "LateMinutes": day.LateMinutes})
+	}
+	absent := []map[string]interface{}{}
+	for _, day := range digest.Absent {
+		absent = append(absent, map[string]interface{}{"Name": day.Name, "Date": day.Date.Format("02-01-2006")})
+	}
+	overtime := []map[string]interface{}{}
+	for _, o := range digest.Overtime {
+		overtime = append(overtime, map[string]interface{}{"Name": o.Name, "Hours": o.Hours})
+	}
+	pending := []map[string]interface{}{}
+	for _, leave := range digest.PendingLeave {
+		pending = append(pending, map[string]interface{}{
+			"Name":      leave.Name,
+			"Type":      leave.Type,
+			"StartDate": leave.StartDate.Format("02-01-2006"),
+			"EndDate":   leave.EndDate.Format("02-01-2006"),
+		})
+	}
+
+	return map[string]interface{}{
+		"Name":         name,
+		"Weekly":       digest.Period == models.DigestWeekly,
+		"Period":       periodLabel,
+		"TeamSize":     digest.TeamSize,
+		"Late":         late,
+		"Absent":       absent,
+		"Overtime":     overtime,
+		"PendingLeave": pending,
+		"HasItems":     len(late)+len(absent)+len(overtime)+len(pending) > 0,
+	}
+}
+
+// formatPeriod menampilkan periode [from, to) sebagai tanggal atau rentang tanggal
+func formatPeriod(from, to time.Time) string {
+	label := from.Format("02-01-2006")
+	if last := to.AddDate(0, 0, -1); !last.Equal(from) {
+		label += " - " + last.Format("02-01-2006")
+	}
+	return label
+}
+
+// RunDigests mengirim ringkasan tim ke setiap manager (user yang punya bawahan aktif) setelah jam kirim.
+// Ringkasan harian dikirim pada hari kerja dan merangkum hari kerja terakhir sebelumnya, sehingga pada hari
+// Senin yang dirangkum adalah hari Jumat. Ringkasan mingguan dikirim pada hari yang dikonfigurasi dan
+// merangkum 7 hari sebelumnya. reminder_log memastikan setiap manager hanya menerima satu per hari.
+func RunDigests(ctx context.Context, now time.Time, ds utils.DigestSchedule, schedule utils.Schedule, build DigestBuilder) (int, error) {
+	if now.Before(ds.SendOn(now)) {
+		return 0, nil
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	type run struct {
+		period, event string
+		from, to      time.Time
+	}
+	runs := []run{}
+	if schedule.IsWorkday(today) {
+		day := schedule.PreviousWorkday(today)
+		runs = append(runs, run{models.DigestDaily, models.NotifyDailyDigest, day, day.AddDate(0, 0, 1)})
+	}
+	if now.Weekday() == ds.Weekday {
+		runs = append(runs, run{models.DigestWeekly, models.NotifyWeeklyDigest, today.AddDate(0, 0, -7), today})
+	}
+
+	sent := 0
+	for _, p := range runs {
+		kind := "digest_" + p.period
+		query := `
+            SELECT m.id::TEXT, m.name, NULL::TIMESTAMPTZ
+            FROM users m
+            WHERE m.deleted_at IS NULL AND COALESCE(m.status, 'active') = 'active'
+              AND EXISTS (
+                  SELECT 1 FROM users r
+                  WHERE r.manager_id = m.id AND r.deleted_at IS NULL AND COALESCE(r.status, 'active') = 'active'
+              )
+              AND NOT EXISTS (SELECT 1 FROM reminder_log rl WHERE rl.user_id = m.id AND rl.kind = $1 AND rl.day = $2::DATE)`
+		managers, err := queryCandidates(ctx, query, kind, today)
+		if err != nil {
+			return sent, err
+		}
+
+		for _, m := range managers {
+			digest, err := build(ctx, m.UserID, p.period, p.from, p.to)
+			if err != nil {
+				log.Println("Error building manager digest:", err, m.UserID)
+				continue
+			}
+			ok, err := sendReminder(ctx, m, kind, today, p.event, notify.TemplateManagerDigest, digestTemplateData(m.Name, digest))
+			if err != nil {
+				log.Println("Error sending manager digest:", err, m.UserID)
+				continue
+			}
+			if ok {
+				sent++
+			}
+		}
+	}
+	return sent, nil
+}
+
+// RunWeeklySummaries mengirim ringkasan kehadiran 7 hari sebelumnya ke setiap user aktif, pada hari dan
+// jam kirim yang sama dengan ringkasan mingguan manager. User memilih channel-nya lewat event weekly_summary.
+func RunWeeklySummaries(ctx context.Context, now time.Time, ds utils.DigestSchedule, build SummaryBuilder) (int, error) {
+	if now.Weekday() != ds.Weekday || now.Before(ds.SendOn(now)) {
+		return 0, nil
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	from := today.AddDate(0, 0, -7)
+
+	query := `
+        SELECT u.id::TEXT, u.name, NULL::TIMESTAMPTZ
+        FROM users u
+        WHERE u.deleted_at IS NULL AND COALESCE(u.status, 'active') = 'active'
+          AND NOT EXISTS (SELECT 1 FROM reminder_log rl WHERE rl.user_id = u.id AND rl.kind = $1 AND rl.day = $2::DATE)`
+	users, err := queryCandidates(ctx, query, models.NotifyWeeklySummary, today.Format("2006-01-02"))
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, u := range users {
+		recap, err := build(ctx, u.UserID, from, today)
+		if err != nil {
+			log.Println("Error building weekly summary:", err, u.UserID)
+			continue
+		}
+		data := map[string]interface{}{
+			"Name":          u.Name,
+			"Period":        formatPeriod(from, today),
+			"Present":       recap.Totals.Present,
+			"Late":          recap.Totals.Late,
+			"LateMinutes":   recap.Totals.LateMinutes,
+			"Absent":        recap.Totals.Absent,
+			"Leave":         recap.Totals.Leave,
+			"WorkedHours":   recap.Totals.WorkedHours,
+			"OvertimeHours": recap.Totals.OvertimeHours,
+		}
+		ok, err := sendReminder(ctx, u, models.NotifyWeeklySummary, today, models.NotifyWeeklySummary, notify.TemplateWeeklySummary, data)
+		if err != nil {
+			log.Println("Error sending weekly summary:", err, u.UserID)
+			continue
+		}
+		if ok {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// StartDigests menjalankan RunDigests dan RunWeeklySummaries setiap 5 menit sampai ctx selesai, jadwal
+// dibaca dari env DIGEST_TIME dan DIGEST_WEEKDAY (lihat utils.GetDigestSchedule)
+func StartDigests(ctx context.Context, build DigestBuilder, summarize SummaryBuilder) {
+	ticker := time.NewTicker(5 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		sent, err := RunDigests(ctx, time.Now(), utils.GetDigestSchedule(), utils.GetSchedule(), build)
+		if err != nil {
+			log.Println("Error running manager digests:", err)
+		} else if sent > 0 {
+			log.Println("Manager digests queued:", sent)
+		}
+
+		sent, err = RunWeeklySummaries(ctx, time.Now(), utils.GetDigestSchedule(), summarize)
+		if err != nil {
+			log.Println("Error running weekly summaries:", err)
+		} else if sent > 0 {
+			log.Println("Weekly summaries queued:", sent)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+
+	"absensi/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// NotifyUser mengantrikan notifikasi event untuk userID lewat channel pilihan user.
+// Template key dirender dalam bahasa user. Jika user memilih none, atau user sudah dihapus,
+// tidak ada yang diantrikan. Untuk push, penerima di outbox adalah id user.
+func NotifyUser(ctx context.Context, tx pgx.Tx, userID, event, key string, data interface{}) error {
+	var email, lang string
+	var prefs models.NotificationPreferences
+	err := tx.QueryRow(ctx, `
+        SELECT email, COALESCE(language, ''), COALESCE(notification_preferences, '{}'::JSONB)
+        FROM users WHERE id = $1 AND deleted_at IS NULL`, userID).Scan(&email, &lang, &prefs)
+	if err == pgx.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("fetch notification preferences: %w", err)
+	}
+
+	channel := prefs.Channel(event)
+	if channel == models.ChannelNone {
+		return nil
+	}
+
+	tmpl, err := LoadTemplate(ctx, tx, key, lang)
+	if err != nil {
+		return err
+	}
+
+	recipient := email
+	if channel == models.ChannelPush {
+		recipient = userID
+	}
+	msg, err := tmpl.Render(recipient, data)
+	if err != nil {
+		return fmt.Errorf("render template %s/%s: %w", key, tmpl.Language, err)
+	}
+	if channel == models.ChannelPush {
+		msg.HTML = ""
+	}
+	return enqueue(ctx, tx, channel, msg)
+}
@@ -0,0 +1,290 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"absensi/database"
+	"absensi/models"
+	"absensi/notify"
+	"absensi/utils"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	outboxBatchSize   = 20
+	outboxMaxAttempts = 8
+	outboxBaseBackoff = 30 * time.Second
+	outboxMaxBackoff  = 6 * time.Hour
+
+	// Pesan berstatus sending lebih lama dari ini dianggap ditinggal worker yang mati
+	outboxStaleAfter = 10 * time.Minute
+
+	// InviteTTL adalah masa berlaku link undangan / set password
+	InviteTTL = 72 * time.Hour
+)
+
+// enqueue menyimpan pesan ke outbox di dalam transaksi tx sehingga hanya terkirim
+// jika data utamanya (misalnya log kehadiran) ikut ter-commit
+func enqueue(ctx context.Context, tx pgx.Tx, channel string, msg notify.Message) error {
+	return insertOutbox(ctx, tx, channel, msg, nil)
+}
+
+// insertOutbox menyimpan pesan ke outbox. passwordTokenUserID diisi untuk pesan yang butuh token set password.
+func insertOutbox(ctx context.Context, tx pgx.Tx, channel string, msg notify.Message, passwordTokenUserID *string) error {
+	query := `
+        INSERT INTO notification_outbox (channel, recipient, subject, body, html_body, status, attempts, max_attempts,
+            next_attempt_at, password_token_user_id, created_at)
+        VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, 0, $7, NOW(), $8, NOW())`
+	_, err := tx.Exec(ctx, query, channel, msg.To, msg.Subject, msg.Text, msg.HTML, models.OutboxStatusPending, outboxMaxAttempts,
+		passwordTokenUserID)
+	return err
+}
+
+// EnqueueEmail mengantrikan email ke outbox di dalam transaksi tx
+func EnqueueEmail(ctx context.Context, tx pgx.Tx, msg notify.Message) error {
+	return enqueue(ctx, tx, models.ChannelEmail, msg)
+}
+
+// EnqueueTemplate merender template key dalam bahasa lang lalu mengantrikannya ke outbox
+func EnqueueTemplate(ctx context.Context, tx pgx.Tx, to, lang, key string, data interface{}) error {
+	tmpl, err := LoadTemplate(ctx, tx, key, lang)
+	if err != nil {
+		return err
+	}
+	msg, err := tmpl.Render(to, data)
+	if err != nil {
+		return fmt.Errorf("render template %s/%s: %w", key, tmpl.Language, err)
+	}
+	return EnqueueEmail(ctx, tx, msg)
+}
+
+// EnqueueInvite mengantrikan email undangan untuk userID. Data template berisi
+// models.PasswordTokenPlaceholder di tempat token; token asli baru dibuat saat pesan dikirim
+// sehingga token tidak pernah tersimpan di outbox maupun terlihat di GET /admin/outbox.
+func EnqueueInvite(ctx context.Context, tx pgx.Tx, to, userID string, data interface{}) error {
+	tmpl, err := LoadTemplate(ctx, tx, notify.TemplateInvite, "")
+	if err != nil {
+		return err
+	}
+	msg, err := tmpl.Render(to, data)
+	if err != nil {
+		return fmt.Errorf("render template %s/%s: %w", notify.TemplateInvite, tmpl.Language, err)
+	}
+	return insertOutbox(ctx, tx, models.ChannelEmail, msg, &userID)
+}
+
+// issuePasswordToken membuat token set password baru untuk userID. Token lama yang belum dipakai
+// dibatalkan agar hanya link dari pengiriman terakhir yang berlaku.
+func issuePasswordToken(ctx context.Context, userID string) (string, error) {
+	token, err := utils.GenerateToken()
+	if err != nil {
+		return "", err
+	}
+
+	tx, err := database.DB.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "DELETE FROM password_tokens WHERE user_id = $1 AND used_at IS NULL", userID); err != nil {
+		return "", err
+	}
+	_, err = tx.Exec(ctx, `INSERT INTO password_tokens (user_id, token_hash, expires_at, created_at) VALUES ($1, $2, $3, NOW())`,
+		userID, utils.HashToken(token), time.Now().Add(InviteTTL))
+	if err != nil {
+		return "", err
+	}
+	return token, tx.Commit(ctx)
+}
+
+// outboxBackoff menghitung jeda sebelum percobaan berikutnya: 30 detik x 2^(attempt-1)
+// dengan jitter 20%, maksimal 6 jam
+func outboxBackoff(attempt int) time.Duration {
+	delay := outboxMaxBackoff
+	if attempt < 20 {
+		if d := outboxBaseBackoff << uint(attempt-1); d < outboxMaxBackoff {
+			delay = d
+		}
+	}
+	jitter := time.Duration(rand.Int63n(int64(delay) / 5))
+	return delay - delay/10 + jitter
+}
+
+// claimOutbox mengambil pesan yang jatuh tempo dan menandainya sending
+func claimOutbox(ctx context.Context) ([]models.OutboxMessage, error) {
+	query := `
+        UPDATE notification_outbox SET status = $1, attempts = attempts + 1, locked_at = NOW()
+        WHERE id IN (
+            SELECT id FROM notification_outbox
+            WHERE (status = $2 AND next_attempt_at <= NOW())
+               OR (status = $1 AND locked_at < $3)
+            ORDER BY next_attempt_at ASC
+            LIMIT $4
+            FOR UPDATE SKIP LOCKED
+        )
+        RETURNING id::TEXT, channel, recipient, subject, body, COALESCE(html_body, ''), attempts, max_attempts,
+            password_token_user_id::TEXT`
+	rows, err := database.DB.Query(ctx, query, models.OutboxStatusSending, models.OutboxStatusPending,
+		time.Now().Add(-outboxStaleAfter), outboxBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := []models.OutboxMessage{}
+	for rows.Next() {
+		var msg models.OutboxMessage
+		if err := rows.Scan(&msg.ID, &msg.Channel, &msg.Recipient, &msg.Subject, &msg.Body, &msg.HTML, &msg.Attempts, &msg.MaxAttempts,
+			&msg.PasswordTokenUserID); err != nil {
+			return nil, err
+		}
+		messages = append(messages, msg)
+	}
+	return messages, rows.Err()
+}
+
+// deliver mengirim satu pesan sesuai channel-nya
+func deliver(ctx context.Context, msg models.OutboxMessage) error {
+	if msg.PasswordTokenUserID != nil {
+		token, err := issuePasswordToken(ctx, *msg.PasswordTokenUserID)
+		if err != nil {
+			return fmt.Errorf("issue password token: %w", err)
+		}
+		replacer := strings.NewReplacer(models.PasswordTokenPlaceholder, token)
+		msg.Subject, msg.Body, msg.HTML = replacer.Replace(msg.Subject), replacer.Replace(msg.Body), replacer.Replace(msg.HTML)
+	}
+
+	switch msg.Channel {
+	case models.ChannelEmail:
+		return notify.Default.Send(ctx, notify.Message{To: msg.Recipient, Subject: msg.Subject, Text: msg.Body, HTML: msg.HTML})
+	case models.ChannelPush:
+		return deliverPush(ctx, msg)
+	}
+	return fmt.Errorf("unknown channel %q", msg.Channel)
+}
+
+// deliverPush mengirim push ke semua perangkat user (recipient berisi id user).
+// Token yang ditolak FCM dihapus; pesan dianggap terkirim jika minimal satu perangkat menerima
+// atau user tidak punya perangkat terdaftar.
+func deliverPush(ctx context.Context, msg models.OutboxMessage) error {
+	if notify.Push == nil {
+		return errors.New("push notifications are not configured")
+	}
+
+	rows, err := database.DB.Query(ctx, "SELECT token FROM device_tokens WHERE user_id = $1", msg.Recipient)
+	if err != nil {
+		return err
+	}
+	tokens := []string{}
+	for rows.Next() {
+		var token string
+		if err := rows.Scan(&token); err != nil {
+			rows.Close()
+			return err
+		}
+		tokens = append(tokens, token)
+	}
+	rows.Close()
+
+	var lastErr error
+	delivered := 0
+	for _, token := range tokens {
+		err := notify.Push.SendPush(ctx, token, notify.Message{To: token, Subject: msg.Subject, Text: msg.Body})
+		switch {
+		case err == nil:
+			delivered++
+		case errors.Is(err, notify.ErrInvalidToken):
+			if _, err := database.DB.Exec(ctx, "DELETE FROM device_tokens WHERE token = $1", token); err != nil {
+				log.Println("Error pruning device token:", err)
+			}
+		default:
+			lastErr = err
+		}
+	}
+
+	if delivered == 0 && lastErr != nil {
+		return lastErr
+	}
+	// Tanpa perangkat (atau semua token sudah tidak valid) pesan tidak boleh ditandai terkirim
+	if delivered == 0 {
+		return errors.New("no registered devices for push")
+	}
+	return nil
+}
+
+// ProcessOutbox mengirim satu batch pesan yang jatuh tempo dan mengembalikan jumlah yang terkirim
+func ProcessOutbox(ctx context.Context) (int, error) {
+	messages, err := claimOutbox(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, msg := range messages {
+		sendErr := deliver(ctx, msg)
+		if sendErr == nil {
+			_, err = database.DB.Exec(ctx, `
+                UPDATE notification_outbox SET status = $1, sent_at = NOW(), last_error = NULL, locked_at = NULL
+                WHERE id = $2`, models.OutboxStatusSent, msg.ID)
+			if err != nil {
+				log.Println("Error marking outbox message sent:", err, msg.ID)
+			}
+			sent++
+			continue
+		}
+
+		status := models.OutboxStatusPending
+		if msg.Attempts >= msg.MaxAttempts {
+			status = models.OutboxStatusDead
+			log.Println("Outbox message moved to dead letter:", msg.ID, sendErr)
+		}
+		_, err = database.DB.Exec(ctx, `
+            UPDATE notification_outbox SET status = $1, next_attempt_at = $2, last_error = $3, locked_at = NULL
+            WHERE id = $4`, status, time.Now().Add(outboxBackoff(msg.Attempts)), sendErr.Error(), msg.ID)
+		if err != nil {
+			log.Println("Error rescheduling outbox message:", err, msg.ID)
+		}
+	}
+	return sent, nil
+}
+
+// StartOutbox menjalankan worker outbox setiap OUTBOX_POLL_SECONDS (default 10) sampai ctx selesai
+func StartOutbox(ctx context.Context) {
+	interval := 10 * time.Second
+	if s, err := strconv.Atoi(os.Getenv("OUTBOX_POLL_SECONDS")); err == nil && s > 0 {
+		interval = time.Duration(s) * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// Kirim terus selama batch penuh agar antrian panjang cepat habis
+		for {
+			sent, err := ProcessOutbox(ctx)
+			if err != nil {
+				log.Println("Error processing outbox:", err)
+				break
+			}
+			if sent < outboxBatchSize {
+				break
+			}
+		}
+	}
+}
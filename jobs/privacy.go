@@ -0,0 +1,124 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+
+	"absensi/database"
+	"absensi/models"
+	"absensi/utils"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrUserNotPurgeable dikembalikan AnonymizeUser jika user tidak ditemukan, sudah dianonimkan,
+// atau belum di-soft delete padahal requireDeleted true
+var ErrUserNotPurgeable = errors.New("user is not soft-deleted")
+
+// AnonymizeUser menghapus data pribadi user di dalam transaksi tx: profil, lokasi dan catatan kehadiran,
+// teks bebas pada pengajuan cuti dan permintaan penghapusan, token perangkat dan password, notifikasi
+// di outbox serta data user pada payload webhook. Waktu kehadiran tetap disimpan untuk payroll.
+// Jika requireDeleted true, user harus sudah di-soft delete terlebih dahulu.
+func AnonymizeUser(ctx context.Context, tx pgx.Tx, userID string, requireDeleted bool) error {
+	// Email asli dibutuhkan untuk menghapus notifikasi yang dikirim ke user ini
+	var email string
+	err := tx.QueryRow(ctx, `
+        SELECT email FROM users
+        WHERE id = $1 AND purged_at IS NULL AND ($2 = FALSE OR deleted_at IS NOT NULL)
+        FOR UPDATE`, userID, requireDeleted).Scan(&email)
+	if err == pgx.ErrNoRows {
+		return ErrUserNotPurgeable
+	}
+	if err != nil {
+		return err
+	}
+
+	randomPassword, err := utils.GenerateToken()
+	if err != nil {
+		return err
+	}
+
+	query := `
+        UPDATE users SET
+            name = 'Deleted User',
+            email = 'deleted-' || id::TEXT || '@invalid',
+            password = $2,
+            phone = NULL,
+            avatar_url = NULL,
+            employee_number = NULL,
+            notification_preferences = NULL,
+            status = $3,
+            deleted_at = COALESCE(deleted_at, NOW()),
+            purged_at = NOW()
+        WHERE id = $1`
+	if _, err := tx.Exec(ctx, query, userID, randomPassword, models.UserStatusInactive); err != nil {
+		return err
+	}
+
+	statements := []struct {
+		sql  string
+		args []interface{}
+	}{
+		{`UPDATE attendance_logs SET latitude = NULL, longitude = NULL, location_name = NULL, notes = NULL
+          WHERE attendance_id IN (SELECT id FROM attendance WHERE user_id = $1)`, []interface{}{userID}},
+		{"UPDATE attendance SET latitude = NULL, longitude = NULL WHERE user_id = $1", []interface{}{userID}},
+		{"UPDATE leave_requests SET reason = NULL WHERE user_id = $1", []interface{}{userID}},
+		{"UPDATE erasure_requests SET reason = NULL WHERE user_id = $1", []interface{}{userID}},
+		{"DELETE FROM fingerprint_mappings WHERE user_id = $1", []interface{}{userID}},
+		{"DELETE FROM password_tokens WHERE user_id = $1", []interface{}{userID}},
+		{"DELETE FROM device_tokens WHERE user_id = $1", []interface{}{userID}},
+		// Penerima email adalah alamat user, penerima push adalah id user
+		{"DELETE FROM notification_outbox WHERE recipient = $1 OR recipient = $2", []interface{}{email, userID}},
+		// Payload webhook berisi email, koordinat dan catatan; hanya id user yang disisakan
+		{`UPDATE webhook_deliveries SET payload = jsonb_set(payload, '{data}', jsonb_build_object('user_id', $1::TEXT, 'redacted', TRUE))
+          WHERE payload->'data'->>'user_id' = $1 OR (event_type = $2 AND payload->'data'->>'id' = $1)`,
+			[]interface{}{userID, models.WebhookUserCreated}},
+	}
+	for _, stmt := range statements {
+		if _, err := tx.Exec(ctx, stmt.sql, stmt.args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunScheduledErasures menjalankan permintaan penghapusan data yang masa retensinya sudah lewat.
+// Setiap permintaan diproses dalam transaksinya sendiri bersama perubahan statusnya menjadi completed.
+func RunScheduledErasures(ctx context.Context) (int, error) {
+	done := 0
+	for {
+		tx, err := database.DB.Begin(ctx)
+		if err != nil {
+			return done, err
+		}
+
+		var requestID, userID string
+		err = tx.QueryRow(ctx, `
+            SELECT id::TEXT, user_id::TEXT FROM erasure_requests
+            WHERE status = $1 AND scheduled_for <= NOW()
+            ORDER BY scheduled_for ASC
+            LIMIT 1
+            FOR UPDATE SKIP LOCKED`, models.ErasureStatusScheduled).Scan(&requestID, &userID)
+		if err == pgx.ErrNoRows {
+			tx.Rollback(ctx)
+			return done, nil
+		}
+		if err == nil {
+			err = AnonymizeUser(ctx, tx, userID, false)
+			if err == ErrUserNotPurgeable {
+				err = nil // Sudah dianonimkan lewat purge
+			}
+		}
+		if err == nil {
+			_, err = tx.Exec(ctx, "UPDATE erasure_requests SET status = $1 WHERE id = $2", models.ErasureStatusCompleted, requestID)
+		}
+		if err == nil {
+			err = tx.Commit(ctx)
+		}
+		if err != nil {
+			tx.Rollback(ctx)
+			return done, err
+		}
+		done++
+	}
+}
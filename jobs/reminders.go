@@ -0,0 +1,186 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"absensi/database"
+	"absensi/models"
+	"absensi/notify"
+	"absensi/utils"
+)
+
+// Jenis pengingat di reminder_log
+const (
+	reminderCheckout = "checkout"
+	reminderCheckin  = "checkin"
+)
+
+// reminderCandidate adalah user yang perlu diingatkan
+type reminderCandidate struct {
+	UserID      string
+	Name        string
+	CheckInTime *time.Time
+}
+
+// sendReminder mengantrikan pengingat untuk satu user. reminder_log memastikan setiap user
+// paling banyak menerima satu pengingat per jenis per hari, walaupun job berjalan di beberapa instance.
+func sendReminder(ctx context.Context, c reminderCandidate, kind string, day time.Time, event, key string, data map[string]interface{}) (bool, error) {
+	tx, err := database.DB.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+        INSERT INTO reminder_log (user_id, kind, day, created_at) VALUES ($1, $2, $3, NOW())
+        ON CONFLICT (user_id, kind, day) DO NOTHING`, c.UserID, kind, day)
+	if err != nil {
+		return false, err
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	if err := NotifyUser(ctx, tx, c.UserID, event, key, data); err != nil {
+		return false, err
+	}
+	return true, tx.Commit(ctx)
+}
+
+// queryCandidates menjalankan query yang mengembalikan (user_id, name, waktu check-in)
+func queryCandidates(ctx context.Context, query string, args ...interface{}) ([]reminderCandidate, error) {
+	rows, err := database.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	candidates := []reminderCandidate{}
+	for rows.Next() {
+		var c reminderCandidate
+		if err := rows.Scan(&c.UserID, &c.Name, &c.CheckInTime); err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, rows.Err()
+}
+
+// RunReminders mengirim pengingat lupa check-out dan belum check-in untuk hari now.
+// Lupa check-out: checkoutDelay setelah jam pulang, user yang check-in terakhirnya belum diikuti check-out.
+// Belum check-in: setelah jam masuk + toleransi pada hari kerja, user aktif yang belum check-in dan tidak cuti.
+func RunReminders(ctx context.Context, now time.Time, schedule utils.Schedule, checkoutDelay time.Duration) (int, error) {
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	dayEnd := dayStart.AddDate(0, 0, 1)
+	// Tanggal lokal dikirim sebagai teks agar tidak dikonversi ke zona waktu sesi database
+	day := dayStart.Format("2006-01-02")
+	sent := 0
+
+	if !now.Before(schedule.EndOn(now).Add(checkoutDelay)) {
+		query := `
+            SELECT u.id::TEXT, u.name, last_in.created_at
+            FROM users u
+            JOIN LATERAL (
+                SELECT MAX(al.created_at) AS created_at
+                FROM attendance_logs al JOIN attendance a ON al.attendance_id = a.id
+                WHERE a.user_id = u.id AND al.type = $1 AND al.created_at >= $3 AND al.created_at < $4
+            ) last_in ON last_in.created_at IS NOT NULL
+            WHERE u.deleted_at IS NULL AND COALESCE(u.status, 'active') = 'active'
+              AND NOT EXISTS (
+                  SELECT 1 FROM attendance_logs al JOIN attendance a ON al.attendance_id = a.id
+                  WHERE a.user_id = u.id AND al.type = $2 AND al.created_at > last_in.created_at
+              )
+              AND NOT EXISTS (SELECT 1 FROM reminder_log rl WHERE rl.user_id = u.id AND rl.kind = $5 AND rl.day = $6::DATE)`
+		candidates, err := queryCandidates(ctx, query, models.LogTypeCheckIn, models.LogTypeCheckOut, dayStart, dayEnd, reminderCheckout, day)
+		if err != nil {
+			return sent, err
+		}
+
+		for _, c := range candidates {
+			data := map[string]interface{}{
+				"Name":        c.Name,
+				"Date":        now.Format("02-01-2006"),
+				"CheckInTime": c.CheckInTime.In(now.Location()).Format("15:04"),
+				"ShiftEnd":    schedule.EndOn(now).Format("15:04"),
+			}
+			ok, err := sendReminder(ctx, c, reminderCheckout, dayStart, models.NotifyCheckoutReminder, notify.TemplateCheckoutReminder, data)
+			if err != nil {
+				log.Println("Error sending checkout reminder:", err, c.UserID)
+				continue
+			}
+			if ok {
+				sent++
+			}
+		}
+	}
+
+	start := schedule.StartOn(now)
+	if schedule.IsWorkday(now) && !now.Before(start.Add(schedule.Grace)) && now.Before(schedule.EndOn(now)) {
+		query := `
+            SELECT u.id::TEXT, u.name, NULL::TIMESTAMPTZ
+            FROM users u
+            WHERE u.deleted_at IS NULL AND COALESCE(u.status, 'active') = 'active'
+              AND NOT EXISTS (
+                  SELECT 1 FROM attendance_logs al JOIN attendance a ON al.attendance_id = a.id
+                  WHERE a.user_id = u.id AND al.type = $1 AND al.created_at >= $2 AND al.created_at < $3
+              )
+              AND NOT EXISTS (
+                  SELECT 1 FROM leave_requests lr
+                  WHERE lr.user_id = u.id AND lr.status = $4 AND lr.start_date <= $6::DATE AND lr.end_date >= $6::DATE
+              )
+              AND NOT EXISTS (SELECT 1 FROM reminder_log rl WHERE rl.user_id = u.id AND rl.kind = $5 AND rl.day = $6::DATE)`
+		candidates, err := queryCandidates(ctx, query, models.LogTypeCheckIn, dayStart, dayEnd, models.LeaveStatusApproved, reminderCheckin, day)
+		if err != nil {
+			return sent, err
+		}
+
+		for _, c := range candidates {
+			data := map[string]interface{}{
+				"Name":       c.Name,
+				"Date":       now.Format("02-01-2006"),
+				"ShiftStart": start.Format("15:04"),
+			}
+			ok, err := sendReminder(ctx, c, reminderCheckin, dayStart, models.NotifyCheckinReminder, notify.TemplateCheckinReminder, data)
+			if err != nil {
+				log.Println("Error sending check-in reminder:", err, c.UserID)
+				continue
+			}
+			if ok {
+				sent++
+			}
+		}
+	}
+
+	return sent, nil
+}
+
+// StartReminders menjalankan RunReminders setiap 5 menit sampai ctx selesai.
+// Pengingat check-out dikirim CHECKOUT_REMINDER_MINUTES (default 30) menit setelah jam pulang.
+func StartReminders(ctx context.Context) {
+	checkoutDelay := 30 * time.Minute
+	if m, err := strconv.Atoi(os.Getenv("CHECKOUT_REMINDER_MINUTES")); err == nil && m >= 0 {
+		checkoutDelay = time.Duration(m) * time.Minute
+	}
+
+	ticker := time.NewTicker(5 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		sent, err := RunReminders(ctx, time.Now(), utils.GetSchedule(), checkoutDelay)
+		if err != nil {
+			log.Println("Error running reminders:", err)
+		} else if sent > 0 {
+			log.Println("Reminders queued:", sent)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
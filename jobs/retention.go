@@ -0,0 +1,154 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"absensi/database"
+	"absensi/models"
+	"absensi/utils"
+)
+
+// retentionLockKey adalah kunci advisory lock Postgres agar hanya satu instance yang menjalankan retensi
+const retentionLockKey = 7301040
+
+// ErrRetentionRunning dikembalikan jika putaran retensi lain sedang berjalan
+var ErrRetentionRunning = errors.New("retention job is already running")
+
+// RunRetention menjalankan satu putaran retensi lokasi dalam satu transaksi:
+// koordinat yang melewati DeleteAfterDays dihapus, sisanya yang melewati CoarsenAfterDays dibulatkan.
+// Hanya kolom latitude/longitude yang diubah; waktu, jenis log, sumber dan nama lokasi tetap utuh
+// sehingga rekap kehadiran dan payroll tidak berubah. Jika dryRun true, perubahan di-rollback
+// dan laporan hanya berisi jumlah baris yang akan diproses.
+func RunRetention(ctx context.Context, policy utils.RetentionPolicy, trigger string, dryRun bool) (models.RetentionRun, error) {
+	run := models.RetentionRun{
+		Trigger:         trigger,
+		DryRun:          dryRun,
+		CoarsenDecimals: policy.CoarsenDecimals,
+		StartedAt:       time.Now(),
+	}
+
+	tx, err := database.DB.Begin(ctx)
+	if err != nil {
+		return run, err
+	}
+	defer tx.Rollback(ctx)
+
+	var locked bool
+	if err := tx.QueryRow(ctx, "SELECT pg_try_advisory_xact_lock($1)", retentionLockKey).Scan(&locked); err != nil {
+		return run, err
+	}
+	if !locked {
+		return run, ErrRetentionRunning
+	}
+
+	if policy.DeleteAfterDays > 0 {
+		cutoff := run.StartedAt.AddDate(0, 0, -policy.DeleteAfterDays)
+		run.DeleteBefore = &cutoff
+
+		tag, err := tx.Exec(ctx, `
+            UPDATE attendance_logs SET latitude = NULL, longitude = NULL
+            WHERE created_at < $1 AND (latitude IS NOT NULL OR longitude IS NOT NULL)`, cutoff)
+		if err != nil {
+			return run, err
+		}
+		run.LogsCleared = tag.RowsAffected()
+
+		tag, err = tx.Exec(ctx, `
+            UPDATE attendance SET latitude = NULL, longitude = NULL
+            WHERE check_in < $1 AND (latitude IS NOT NULL OR longitude IS NOT NULL)`, cutoff)
+		if err != nil {
+			return run, err
+		}
+		run.AttendanceCleared = tag.RowsAffected()
+	}
+
+	if policy.CoarsenAfterDays > 0 {
+		cutoff := run.StartedAt.AddDate(0, 0, -policy.CoarsenAfterDays)
+		run.CoarsenBefore = &cutoff
+
+		// coarsened_at menandai baris yang sudah dibulatkan agar tidak diproses ulang
+		tag, err := tx.Exec(ctx, `
+            UPDATE attendance_logs SET
+                latitude = ROUND(latitude::NUMERIC, $2)::DOUBLE PRECISION,
+                longitude = ROUND(longitude::NUMERIC, $2)::DOUBLE PRECISION,
+                coarsened_at = NOW()
+            WHERE created_at < $1 AND coarsened_at IS NULL AND latitude IS NOT NULL`, cutoff, policy.CoarsenDecimals)
+		if err != nil {
+			return run, err
+		}
+		run.LogsCoarsened = tag.RowsAffected()
+
+		tag, err = tx.Exec(ctx, `
+            UPDATE attendance SET
+                latitude = ROUND(latitude::NUMERIC, $2)::DOUBLE PRECISION,
+                longitude = ROUND(longitude::NUMERIC, $2)::DOUBLE PRECISION,
+                coarsened_at = NOW()
+            WHERE check_in < $1 AND coarsened_at IS NULL AND latitude IS NOT NULL`, cutoff, policy.CoarsenDecimals)
+		if err != nil {
+			return run, err
+		}
+		run.AttendanceCoarsened = tag.RowsAffected()
+	}
+
+	run.FinishedAt = time.Now()
+	if dryRun {
+		return run, nil
+	}
+
+	query := `
+        INSERT INTO retention_runs (trigger, coarsen_before, delete_before, coarsen_decimals,
+            logs_coarsened, attendance_coarsened, logs_cleared, attendance_cleared, started_at, finished_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+        RETURNING id::TEXT`
+	err = tx.QueryRow(ctx, query, run.Trigger, run.CoarsenBefore, run.DeleteBefore, run.CoarsenDecimals,
+		run.LogsCoarsened, run.AttendanceCoarsened, run.LogsCleared, run.AttendanceCleared, run.StartedAt, run.FinishedAt).Scan(&run.ID)
+	if err != nil {
+		return run, err
+	}
+
+	return run, tx.Commit(ctx)
+}
+
+// StartRetention menjalankan RunRetention sesuai interval kebijakan sampai ctx selesai, bersama
+// permintaan penghapusan data yang masa retensinya sudah lewat. Jika kebijakan lokasi tidak
+// dikonfigurasi, hanya penghapusan data terjadwal yang dijalankan.
+func StartRetention(ctx context.Context) {
+	policy := utils.GetRetentionPolicy()
+	if !policy.Enabled() {
+		log.Println("Location retention disabled: LOCATION_COARSEN_DAYS and LOCATION_DELETE_DAYS are not set")
+	}
+
+	ticker := time.NewTicker(policy.Interval)
+	defer ticker.Stop()
+
+	for {
+		if policy.Enabled() {
+			run, err := RunRetention(ctx, policy, models.RetentionTriggerSchedule, false)
+			switch {
+			case err == ErrRetentionRunning:
+				log.Println("Location retention skipped: another run is in progress")
+			case err != nil:
+				log.Println("Error running location retention:", err)
+			default:
+				log.Printf("Location retention done: %d logs / %d attendance coarsened, %d logs / %d attendance cleared",
+					run.LogsCoarsened, run.AttendanceCoarsened, run.LogsCleared, run.AttendanceCleared)
+			}
+		}
+
+		erased, err := RunScheduledErasures(ctx)
+		if err != nil {
+			log.Println("Error running scheduled erasures:", err)
+		} else if erased > 0 {
+			log.Println("Scheduled erasures completed:", erased)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
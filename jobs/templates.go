@@ -0,0 +1,40 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+
+	"absensi/notify"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// queryRower dipenuhi oleh koneksi database maupun transaksi
+type queryRower interface {
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// LoadTemplate mengambil template key dalam bahasa lang. Template yang diubah admin di
+// notification_templates diutamakan, lalu template bawaan, lalu template bawaan bahasa default.
+func LoadTemplate(ctx context.Context, db queryRower, key, lang string) (notify.Template, error) {
+	lang = notify.NormalizeLanguage(lang)
+
+	tmpl := notify.Template{Key: key, Language: lang}
+	err := db.QueryRow(ctx, `
+        SELECT subject, text_body, COALESCE(html_body, '') FROM notification_templates
+        WHERE key = $1 AND language = $2`, key, lang).Scan(&tmpl.Subject, &tmpl.Text, &tmpl.HTML)
+	if err == nil {
+		return tmpl, nil
+	}
+	if err != pgx.ErrNoRows {
+		return tmpl, err
+	}
+
+	if t, ok := notify.DefaultTemplate(key, lang); ok {
+		return t, nil
+	}
+	if t, ok := notify.DefaultTemplate(key, notify.LangID); ok {
+		return t, nil
+	}
+	return tmpl, fmt.Errorf("unknown notification template %q", key)
+}
@@ -0,0 +1,274 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"absensi/database"
+	"absensi/models"
+	"absensi/utils"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	webhookBatchSize   = 20
+	webhookMaxAttempts = 8
+	webhookTimeout     = 10 * time.Second
+)
+
+// execer dipenuhi oleh koneksi database maupun transaksi
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+}
+
+// webhookClient hanya terhubung ke alamat publik dan tidak mengikuti redirect, sehingga URL webhook
+// tidak bisa dipakai untuk menjangkau layanan internal (SSRF)
+var webhookClient = &http.Client{
+	Timeout: webhookTimeout,
+	Transport: &http.Transport{
+		DialContext:         dialWebhook,
+		TLSHandshakeTimeout: webhookTimeout,
+		MaxIdleConns:        10,
+		IdleConnTimeout:     90 * time.Second,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// dialWebhook me-resolve host lalu menolak koneksi jika salah satu alamatnya bukan alamat publik.
+// Koneksi dibuka ke IP yang sudah diperiksa, bukan ke hostname, agar hasil DNS tidak bisa berubah
+// di antara pemeriksaan dan koneksi (DNS rebinding).
+func dialWebhook(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	ips, err := net.DefaultResolver.LookupIP(ctx, "ip", host)
+	if err != nil {
+		return nil, err
+	}
+	for _, ip := range ips {
+		if !utils.WebhookTargetAllowed(ip) {
+			return nil, fmt.Errorf("webhook host %s resolves to non-public address %s", host, ip)
+		}
+	}
+
+	dialer := &net.Dialer{Timeout: webhookTimeout}
+	var dialErr error
+	for _, ip := range ips {
+		conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		dialErr = err
+	}
+	if dialErr == nil {
+		dialErr = fmt.Errorf("no addresses found for %s", host)
+	}
+	return nil, dialErr
+}
+
+// newWebhookPayload membungkus data event dengan id unik yang sama untuk semua endpoint
+func newWebhookPayload(eventType string, data interface{}) ([]byte, error) {
+	token, err := utils.GenerateToken()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(models.WebhookPayload{ID: "evt_" + token[:24], Type: eventType, OccurredAt: time.Now(), Data: data})
+}
+
+// EnqueueWebhookEvent membuat antrian pengiriman event ke setiap webhook aktif yang berlangganan eventType.
+// Jika db adalah transaksi, event hanya dikirim jika transaksi ter-commit.
+func EnqueueWebhookEvent(ctx context.Context, db execer, eventType string, data interface{}) error {
+	payload, err := newWebhookPayload(eventType, data)
+	if err != nil {
+		return err
+	}
+
+	query := `
+        INSERT INTO webhook_deliveries (endpoint_id, event_type, payload, status, attempts, next_attempt_at, created_at)
+        SELECT id, $1, $2::JSONB, $3, 0, NOW(), NOW()
+        FROM webhook_endpoints
+        WHERE active AND $1 = ANY(event_types)`
+	_, err = db.Exec(ctx, query, eventType, string(payload), models.WebhookDeliveryPending)
+	return err
+}
+
+// webhookJob adalah pengiriman yang sudah diklaim worker
+type webhookJob struct {
+	ID        string
+	EventType string
+	Payload   []byte
+	Attempts  int
+	URL       string
+	Secret    string
+}
+
+// claimWebhooks mengambil pengiriman yang jatuh tempo. Jika deliveryID diisi, hanya pengiriman itu yang diambil.
+func claimWebhooks(ctx context.Context, deliveryID string) ([]webhookJob, error) {
+	query := `
+        UPDATE webhook_deliveries d SET status = $1, attempts = d.attempts + 1, locked_at = NOW()
+        FROM webhook_endpoints e
+        WHERE e.id = d.endpoint_id AND d.id IN (
+            SELECT wd.id FROM webhook_deliveries wd JOIN webhook_endpoints we ON we.id = wd.endpoint_id
+            WHERE we.active AND (
+                ($4 <> '' AND wd.id::TEXT = $4)
+                OR ($4 = '' AND ((wd.status = $2 AND wd.next_attempt_at <= NOW()) OR (wd.status = $1 AND wd.locked_at < $3)))
+            )
+            ORDER BY wd.next_attempt_at ASC
+            LIMIT $5
+            FOR UPDATE OF wd SKIP LOCKED
+        )
+        RETURNING d.id::TEXT, d.event_type, d.payload::TEXT, d.attempts, e.url, e.secret`
+	rows, err := database.DB.Query(ctx, query, models.WebhookDeliverySending, models.WebhookDeliveryPending,
+		time.Now().Add(-outboxStaleAfter), deliveryID, webhookBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []webhookJob{}
+	for rows.Next() {
+		var job webhookJob
+		var payload string
+		if err := rows.Scan(&job.ID, &job.EventType, &payload, &job.Attempts, &job.URL, &job.Secret); err != nil {
+			return nil, err
+		}
+		job.Payload = []byte(payload)
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+// postWebhook mengirim payload bertanda tangan ke endpoint
+func postWebhook(ctx context.Context, job webhookJob) (int, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, job.URL, bytes.NewReader(job.Payload))
+	if err != nil {
+		return 0, "", err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Absensi-Webhooks/1.0")
+	req.Header.Set("X-Absensi-Event", job.EventType)
+	req.Header.Set("X-Absensi-Delivery", job.ID)
+	req.Header.Set("X-Absensi-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-Absensi-Signature", utils.SignWebhook(job.Secret, timestamp, job.Payload))
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, string(body), fmt.Errorf("endpoint returned %d", resp.StatusCode)
+	}
+	return resp.StatusCode, string(body), nil
+}
+
+// processWebhook mengirim satu pengiriman dan mencatat hasilnya
+func processWebhook(ctx context.Context, job webhookJob) bool {
+	status, body, sendErr := postWebhook(ctx, job)
+	var responseStatus *int
+	if status != 0 {
+		responseStatus = &status
+	}
+
+	if sendErr == nil {
+		_, err := database.DB.Exec(ctx, `
+            UPDATE webhook_deliveries SET status = $1, response_status = $2, response_body = $3, last_error = NULL,
+                delivered_at = NOW(), locked_at = NULL
+            WHERE id = $4`, models.WebhookDeliveryDelivered, responseStatus, body, job.ID)
+		if err != nil {
+			log.Println("Error marking webhook delivered:", err, job.ID)
+		}
+		return true
+	}
+
+	next := models.WebhookDeliveryPending
+	if job.Attempts >= webhookMaxAttempts {
+		next = models.WebhookDeliveryFailed
+	}
+	_, err := database.DB.Exec(ctx, `
+        UPDATE webhook_deliveries SET status = $1, response_status = $2, response_body = $3, last_error = $4,
+            next_attempt_at = $5, locked_at = NULL
+        WHERE id = $6`, next, responseStatus, body, sendErr.Error(), time.Now().Add(outboxBackoff(job.Attempts)), job.ID)
+	if err != nil {
+		log.Println("Error rescheduling webhook delivery:", err, job.ID)
+	}
+	return false
+}
+
+// ProcessWebhooks mengirim satu batch webhook yang jatuh tempo dan mengembalikan jumlah yang diklaim
+func ProcessWebhooks(ctx context.Context) (int, error) {
+	jobs, err := claimWebhooks(ctx, "")
+	if err != nil {
+		return 0, err
+	}
+	for _, job := range jobs {
+		processWebhook(ctx, job)
+	}
+	return len(jobs), nil
+}
+
+// SendTestWebhook langsung mengirim event webhook.test ke satu endpoint dan mengembalikan id pengirimannya
+func SendTestWebhook(ctx context.Context, endpointID string) (string, error) {
+	payload, err := newWebhookPayload(models.WebhookTest, map[string]string{"message": "Test event from Absensi"})
+	if err != nil {
+		return "", err
+	}
+
+	var deliveryID string
+	err = database.DB.QueryRow(ctx, `
+        INSERT INTO webhook_deliveries (endpoint_id, event_type, payload, status, attempts, next_attempt_at, created_at)
+        VALUES ($1, $2, $3::JSONB, $4, 0, NOW(), NOW())
+        RETURNING id::TEXT`, endpointID, models.WebhookTest, string(payload), models.WebhookDeliveryPending).Scan(&deliveryID)
+	if err != nil {
+		return "", err
+	}
+
+	jobs, err := claimWebhooks(ctx, deliveryID)
+	if err != nil {
+		return deliveryID, err
+	}
+	for _, job := range jobs {
+		processWebhook(ctx, job)
+	}
+	return deliveryID, nil
+}
+
+// StartWebhooks menjalankan worker webhook setiap 5 detik sampai ctx selesai
+func StartWebhooks(ctx context.Context) {
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for {
+			claimed, err := ProcessWebhooks(ctx)
+			if err != nil {
+				log.Println("Error processing webhooks:", err)
+				break
+			}
+			if claimed < webhookBatchSize {
+				break
+			}
+		}
+	}
+}
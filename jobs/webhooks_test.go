@@ -0,0 +1,47 @@
+package jobs
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPostWebhookRejectsPrivateAddresses(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	t.Setenv("WEBHOOK_ALLOW_PRIVATE", "")
+	_, _, err := postWebhook(context.Background(), webhookJob{ID: "d1", EventType: "webhook.test", Payload: []byte(`{}`), URL: server.URL})
+	if err == nil || !strings.Contains(err.Error(), "non-public address") {
+		t.Fatalf("postWebhook() error = %v, want non-public address error", err)
+	}
+	if called {
+		t.Fatal("request reached a loopback endpoint")
+	}
+}
+
+func TestPostWebhookDoesNotFollowRedirects(t *testing.T) {
+	redirected := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/internal" {
+			redirected = true
+			return
+		}
+		http.Redirect(w, r, "/internal", http.StatusFound)
+	}))
+	defer server.Close()
+
+	t.Setenv("WEBHOOK_ALLOW_PRIVATE", "true")
+	status, _, err := postWebhook(context.Background(), webhookJob{ID: "d1", EventType: "webhook.test", Payload: []byte(`{}`), URL: server.URL + "/hook"})
+	if err == nil || status != http.StatusFound {
+		t.Fatalf("postWebhook() = %d, %v; want 302 and an error", status, err)
+	}
+	if redirected {
+		t.Fatal("redirect was followed")
+	}
+}
@@ -1,8 +1,12 @@
 package main
 
 import (
+	"absensi/controller"
 	"absensi/database"
+	"absensi/jobs"
+	"absensi/notify"
 	"absensi/routes"
+	"context"
 	"log"
 	"net/http"
 	"os"
@@ -20,6 +24,41 @@ func main() {
 
 	// Inisialisasi Supabase
 	database.InitDB()
+	defer database.Close()
+
+	// Subcommand migrate: absensi migrate [up | down [n] | status]
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(os.Args[2:])
+		return
+	}
+
+	// MIGRATE_ON_START=true menerapkan migrasi yang belum dijalankan sebelum server start
+	if os.Getenv("MIGRATE_ON_START") == "true" {
+		if _, err := database.MigrateUp(context.Background()); err != nil {
+			log.Fatal("Failed to run migrations: ", err)
+		}
+	}
+
+	// Provider notifikasi dipilih lewat env NOTIFIER
+	notifier, err := notify.FromEnv()
+	if err != nil {
+		log.Fatal("Invalid notifier configuration: ", err)
+	}
+	notify.Default = notifier
+
+	// Push notification FCM aktif jika FIREBASE_CREDENTIALS atau FCM_ENDPOINT diisi
+	push, err := notify.PushFromEnv(context.Background())
+	if err != nil {
+		log.Fatal("Invalid push configuration: ", err)
+	}
+	notify.Push = push
+
+	// Job background
+	go jobs.StartRetention(context.Background())
+	go jobs.StartOutbox(context.Background())
+	go jobs.StartReminders(context.Background())
+	go jobs.StartWebhooks(context.Background())
+	go jobs.StartDigests(context.Background(), controller.BuildManagerDigest, controller.BuildWeeklySummary)
 
 	// Setup router
 	router := routes.SetupRoutes(&auth.Client{})
@@ -1,8 +1,10 @@
 package middleware
 
 import (
+	"absensi/database"
 	"absensi/utils"
 	"context"
+	"log"
 	"net/http"
 	"strings"
 
@@ -11,22 +13,36 @@ import (
 
 // Middleware untuk verifikasi token dan ekstraksi user_id
 func AuthMiddleware(next http.Handler) http.Handler {
+	return authenticate(next, false)
+}
+
+// StreamAuthMiddleware sama dengan AuthMiddleware, tetapi juga menerima token lewat query ?access_token=
+// karena EventSource di browser tidak bisa mengirim header Authorization. Hanya dipakai untuk endpoint SSE.
+func StreamAuthMiddleware(next http.Handler) http.Handler {
+	return authenticate(next, true)
+}
+
+func authenticate(next http.Handler, allowQuery bool) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Ambil token dari header Authorization
 		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
+		var tokenString string
+		switch {
+		case authHeader != "":
+			// Cek format "Bearer <token>"
+			parts := strings.Split(authHeader, " ")
+			if len(parts) != 2 || parts[0] != "Bearer" {
+				http.Error(w, "Invalid token format", http.StatusUnauthorized)
+				return
+			}
+			tokenString = parts[1]
+		case allowQuery && r.URL.Query().Get("access_token") != "":
+			tokenString = r.URL.Query().Get("access_token")
+		default:
 			http.Error(w, "Authorization header missing", http.StatusUnauthorized)
 			return
 		}
 
-		// Cek format "Bearer <token>"
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			http.Error(w, "Invalid token format", http.StatusUnauthorized)
-			return
-		}
-		tokenString := parts[1]
-
 		// Verifikasi token
 		userID, err := utils.ValidateJWT(tokenString)
 		if err != nil {
@@ -34,6 +50,21 @@ func AuthMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
+		// Token tetap berlaku sampai expired, jadi status akun dicek di setiap request
+		var active bool
+		err = database.DB.QueryRow(r.Context(),
+			"SELECT EXISTS (SELECT 1 FROM users WHERE id = $1 AND deleted_at IS NULL AND COALESCE(status, 'active') = 'active')",
+			userID).Scan(&active)
+		if err != nil {
+			log.Println("Error checking user status:", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if !active {
+			http.Error(w, "Account is deactivated", http.StatusForbidden)
+			return
+		}
+
 		// Tambahkan user_id ke context
 		ctx := context.WithValue(r.Context(), "user_id", userID)
 		next.ServeHTTP(w, r.WithContext(ctx))
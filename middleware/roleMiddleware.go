@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"absensi/database"
+	"context"
+	"log"
+	"net/http"
+)
+
+// RequireRole membatasi akses hanya untuk user dengan salah satu role yang diberikan.
+// Harus dipasang setelah AuthMiddleware karena membutuhkan user_id di context.
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := r.Context().Value("user_id").(string)
+			if !ok || userID == "" {
+				http.Error(w, "User not authenticated", http.StatusUnauthorized)
+				return
+			}
+
+			// User yang dinonaktifkan atau dihapus kehilangan hak aksesnya walaupun token masih berlaku
+			var role string
+			err := database.DB.QueryRow(r.Context(),
+				"SELECT role FROM users WHERE id = $1 AND deleted_at IS NULL AND COALESCE(status, 'active') = 'active'", userID).Scan(&role)
+			if err != nil {
+				log.Println("Error fetching user role:", err)
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+
+			for _, allowed := range roles {
+				if role == allowed {
+					// Simpan role ke context agar handler tidak perlu query ulang
+					ctx := context.WithValue(r.Context(), "role", role)
+					next.ServeHTTP(w, r.WithContext(ctx))
+					return
+				}
+			}
+
+			http.Error(w, "Forbidden", http.StatusForbidden)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"absensi/database"
+)
+
+// runMigrate menjalankan subcommand migrate:
+//
+//	migrate up         menerapkan semua migrasi yang belum dijalankan (default)
+//	migrate down [n]   membatalkan n migrasi terakhir (default 1)
+//	migrate status     menampilkan versi yang sudah dan belum diterapkan
+func runMigrate(args []string) {
+	ctx := context.Background()
+
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	switch command {
+	case "up":
+		applied, err := database.MigrateUp(ctx)
+		if err != nil {
+			log.Fatal("Migration failed: ", err)
+		}
+		if len(applied) == 0 {
+			log.Println("Database schema is up to date")
+		}
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				log.Fatal("Invalid number of migrations to roll back: ", args[1])
+			}
+			steps = n
+		}
+		if _, err := database.MigrateDown(ctx, steps); err != nil {
+			log.Fatal("Rollback failed: ", err)
+		}
+
+	case "status":
+		status, err := database.GetMigrationStatus(ctx)
+		if err != nil {
+			log.Fatal("Failed to read migration status: ", err)
+		}
+		for _, s := range status {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-28s %s\n", s.Version, s.Name, applied)
+		}
+
+	default:
+		fmt.Fprintln(os.Stderr, "usage: migrate [up | down [n] | status]")
+		os.Exit(2)
+	}
+}
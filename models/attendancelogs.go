@@ -1,15 +1,23 @@
 package models
 
-import (
-	"time"
+import "time"
 
-	uuid "github.com/jackc/pgx/pgtype/ext/gofrs-uuid"
+// Jenis log pada attendance_logs
+const (
+	LogTypeCheckIn    = "check_in"
+	LogTypeCheckOut   = "check_out"
+	LogTypeBreakStart = "break_start"
+	LogTypeBreakEnd   = "break_end"
+	LogTypeVisit      = "visit"
 )
 
 type AttendanceLog struct {
-    ID           uuid.UUID `json:"id"`
-    AttendanceID uuid.UUID `json:"attendance_id"`
+    ID           string    `json:"id"`
+    AttendanceID string    `json:"attendance_id"`
+    Type         string    `json:"type"`
+    LocationName string    `json:"location_name,omitempty"`
+    Notes        string    `json:"notes,omitempty"`
     Latitude     float64   `json:"latitude"`
     Longitude    float64   `json:"longitude"`
     CreatedAt    time.Time `json:"created_at"`
-}
\ No newline at end of file
+}
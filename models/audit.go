@@ -0,0 +1,61 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Aksi yang dicatat di audit log
+const (
+	AuditUserRoleChanged   = "user.role_changed"
+	AuditUserDeleted       = "user.deleted"
+	AuditUserDeactivated   = "user.deactivated"
+	AuditUserActivated     = "user.activated"
+	AuditUserRestored      = "user.restored"
+	AuditUserPurged        = "user.purged"
+	AuditUserOrgChanged    = "user.org_changed"
+	AuditUsersImported     = "users.imported"
+	AuditDepartmentCreated = "department.created"
+	AuditDepartmentUpdated = "department.updated"
+	AuditDepartmentDeleted = "department.deleted"
+	AuditTeamCreated       = "team.created"
+	AuditTeamUpdated       = "team.updated"
+	AuditTeamDeleted       = "team.deleted"
+	AuditLeaveDecided      = "leave.decided"
+	AuditPunchesImported   = "attendance.punches_imported"
+	AuditMappingSaved      = "fingerprint_mapping.saved"
+	AuditMappingDeleted    = "fingerprint_mapping.deleted"
+	AuditDataExported      = "user.data_exported"
+	AuditErasureDecided    = "user.erasure_decided"
+	AuditRetentionRun      = "retention.run"
+	AuditOutboxRedriven    = "outbox.redriven"
+	AuditTemplateUpdated   = "notification_template.updated"
+	AuditTemplateReset     = "notification_template.reset"
+	AuditWebhookCreated    = "webhook.created"
+	AuditWebhookUpdated    = "webhook.updated"
+	AuditWebhookDeleted    = "webhook.deleted"
+	AuditWebhookRotated    = "webhook.secret_rotated"
+	AuditWebhookRedriven   = "webhook_delivery.redriven"
+)
+
+// AuditLog model for audit_logs table (append-only)
+type AuditLog struct {
+	ID         string          `json:"id"`
+	ActorID    string          `json:"actor_id"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   string          `json:"target_id"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	IP         string          `json:"ip"`
+	UserAgent  string          `json:"user_agent"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// AuditLogPage adalah satu halaman hasil query audit log
+type AuditLogPage struct {
+	Data     []AuditLog `json:"data"`
+	Total    int        `json:"total"`
+	Page     int        `json:"page"`
+	PageSize int        `json:"page_size"`
+}
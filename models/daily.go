@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// Status kehadiran harian pada rekap
+const (
+	DailyStatusPresent    = "present"
+	DailyStatusLate       = "late"
+	DailyStatusNoCheckOut = "no_check_out"
+	DailyStatusAbsent     = "absent"
+	DailyStatusLeave      = "leave"
+	DailyStatusOff        = "off"
+
+	// Tanggal sebelum user dibuat atau setelah user dihapus, tidak dihitung hadir maupun absen
+	DailyStatusNotEmployed = "not_employed"
+)
+
+// DailyAttendance adalah rekap kehadiran satu user pada satu hari,
+// dihitung dari check-in pertama dan check-out terakhir di attendance_logs
+type DailyAttendance struct {
+	UserID         string     `json:"user_id"`
+	Name           string     `json:"name"`
+	DepartmentName string     `json:"department_name,omitempty"`
+	Date           time.Time  `json:"date"`
+	CheckIn        *time.Time `json:"check_in,omitempty"`
+	CheckOut       *time.Time `json:"check_out,omitempty"`
+	Status         string     `json:"status"`
+	LeaveType      string     `json:"leave_type,omitempty"`
+	LateMinutes    int        `json:"late_minutes"`
+	WorkedHours    float64    `json:"worked_hours"`
+	OvertimeHours  float64    `json:"overtime_hours"`
+	Latitude       *float64   `json:"latitude,omitempty"`
+	Longitude      *float64   `json:"longitude,omitempty"`
+}
+
+// RecapTotals adalah total kehadiran dalam satu periode
+type RecapTotals struct {
+	Present       int     `json:"present"`
+	Late          int     `json:"late"`
+	LateMinutes   int     `json:"late_minutes"`
+	Absent        int     `json:"absent"`
+	Leave         int     `json:"leave"`
+	WorkedHours   float64 `json:"worked_hours"`
+	OvertimeHours float64 `json:"overtime_hours"`
+}
+
+// MonthlyRecap adalah rekap absensi bulanan satu user, lengkap satu baris per tanggal
+type MonthlyRecap struct {
+	UserID         string            `json:"user_id"`
+	Name           string            `json:"name"`
+	DepartmentID   string            `json:"department_id,omitempty"`
+	DepartmentName string            `json:"department_name,omitempty"`
+	Days           []DailyAttendance `json:"days"`
+	Totals         RecapTotals       `json:"totals"`
+}
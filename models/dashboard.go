@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// Status kehadiran harian pada dashboard
+const (
+	DayStatusAbsent       = "absent"
+	DayStatusOnLeave      = "on_leave"
+	DayStatusStillWorking = "still_working"
+	DayStatusCheckedOut   = "checked_out"
+	DayStatusOff          = "off" // Bukan hari kerja dan tidak check-in
+)
+
+// TeamMemberStatus adalah ringkasan kehadiran satu anggota tim pada hari tertentu
+type TeamMemberStatus struct {
+	UserID        string     `json:"user_id"`
+	Name          string     `json:"name"`
+	Status        string     `json:"status"`
+	Late          bool       `json:"late"`
+	LateMinutes   int        `json:"late_minutes"`
+	FirstCheckIn  *time.Time `json:"first_check_in,omitempty"`
+	LastEventType string     `json:"last_event_type,omitempty"`
+	LastEventAt   *time.Time `json:"last_event_at,omitempty"`
+	LastLatitude  *float64   `json:"last_latitude,omitempty"`
+	LastLongitude *float64   `json:"last_longitude,omitempty"`
+}
+
+// TeamDashboardCounts adalah jumlah anggota tim per status
+type TeamDashboardCounts struct {
+	Total        int `json:"total"`
+	CheckedIn    int `json:"checked_in"`
+	Late         int `json:"late"`
+	OnLeave      int `json:"on_leave"`
+	Absent       int `json:"absent"`
+	StillWorking int `json:"still_working"`
+	CheckedOut   int `json:"checked_out"`
+	Off          int `json:"off"`
+}
+
+// TeamDashboard adalah respon dashboard kehadiran tim untuk satu hari
+type TeamDashboard struct {
+	Date    string              `json:"date"`
+	Counts  TeamDashboardCounts `json:"counts"`
+	Members []TeamMemberStatus  `json:"members"`
+}
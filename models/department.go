@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Department model for departments table
+type Department struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Team model for teams table, setiap team berada di bawah satu department
+type Team struct {
+	ID           string    `json:"id"`
+	DepartmentID string    `json:"department_id"`
+	Name         string    `json:"name"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// DepartmentAttendance mengelompokkan rekap kehadiran harian per department
+type DepartmentAttendance struct {
+	DepartmentID   string            `json:"department_id"`
+	DepartmentName string            `json:"department_name"`
+	Attendances    []DailyAttendance `json:"attendances"`
+}
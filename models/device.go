@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// DeviceToken adalah token FCM milik satu perangkat user
+type DeviceToken struct {
+	ID         string    `json:"id"`
+	Token      string    `json:"token"`
+	Platform   string    `json:"platform"` // android, ios atau web
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// Periode ringkasan untuk manager
+const (
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
+// DigestOvertime adalah total lembur satu bawahan dalam periode ringkasan
+type DigestOvertime struct {
+	UserID string  `json:"user_id"`
+	Name   string  `json:"name"`
+	Hours  float64 `json:"hours"`
+}
+
+// DigestLeave adalah pengajuan cuti bawahan yang masih menunggu keputusan
+type DigestLeave struct {
+	LeaveRequest
+	Name string `json:"name"`
+}
+
+// ManagerDigest adalah ringkasan kehadiran tim seorang manager untuk periode [From, To)
+type ManagerDigest struct {
+	ManagerID    string            `json:"manager_id"`
+	Period       string            `json:"period"`
+	From         time.Time         `json:"from"`
+	To           time.Time         `json:"to"`
+	TeamSize     int               `json:"team_size"`
+	Late         []DailyAttendance `json:"late"`
+	Absent       []DailyAttendance `json:"absent"`
+	Overtime     []DigestOvertime  `json:"overtime"`
+	PendingLeave []DigestLeave     `json:"pending_leave"`
+	Totals       RecapTotals       `json:"totals"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// FingerprintMapping memetakan user id di mesin fingerprint ke users.id
+type FingerprintMapping struct {
+	DeviceID     string    `json:"device_id"`
+	DeviceUserID string    `json:"device_user_id"`
+	UserID       string    `json:"user_id"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Hasil import per baris
+const (
+	ImportResultImported  = "imported"
+	ImportResultSkipped   = "skipped"
+	ImportResultUnmatched = "unmatched"
+	ImportResultInvalid   = "invalid"
+)
+
+// ImportLine adalah hasil import satu baris file punch
+type ImportLine struct {
+	Line         int        `json:"line"`
+	DeviceUserID string     `json:"device_user_id,omitempty"`
+	UserID       string     `json:"user_id,omitempty"`
+	Timestamp    *time.Time `json:"timestamp,omitempty"`
+	Type         string     `json:"type,omitempty"`
+	Result       string     `json:"result"`
+	Reason       string     `json:"reason,omitempty"`
+}
+
+// ImportReport adalah ringkasan import file punch fingerprint
+type ImportReport struct {
+	DryRun    bool         `json:"dry_run"`
+	Total     int          `json:"total"`
+	Imported  int          `json:"imported"`
+	Skipped   int          `json:"skipped"`
+	Unmatched int          `json:"unmatched"`
+	Invalid   int          `json:"invalid"`
+	Lines     []ImportLine `json:"lines"`
+}
@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+// NotificationTemplate adalah template notifikasi yang berlaku untuk satu key dan bahasa
+type NotificationTemplate struct {
+	Key        string     `json:"key"`
+	Language   string     `json:"language"`
+	Subject    string     `json:"subject"`
+	Text       string     `json:"text"`
+	HTML       string     `json:"html"`
+	Customized bool       `json:"customized"` // true jika diubah admin, false jika template bawaan
+	UpdatedAt  *time.Time `json:"updated_at,omitempty"`
+}
+
+// Event notifikasi yang bisa diatur user
+const (
+	NotifyCheckInReceipt   = "check_in_receipt" // Tanda terima check-in dan check-out
+	NotifyCheckoutReminder = "checkout_reminder"
+	NotifyCheckinReminder  = "checkin_reminder"
+	NotifyLeaveDecision    = "leave_decision"
+	NotifyWeeklySummary    = "weekly_summary" // Ringkasan kehadiran mingguan milik user sendiri
+	NotifyDailyDigest      = "daily_digest"   // Ringkasan harian tim, hanya untuk manager
+	NotifyWeeklyDigest     = "weekly_digest"  // Ringkasan mingguan tim, hanya untuk manager
+)
+
+// NotifyEvents adalah semua event notifikasi beserta channel default-nya
+var NotifyEvents = map[string]string{
+	NotifyCheckInReceipt:   ChannelEmail,
+	NotifyCheckoutReminder: ChannelEmail,
+	NotifyCheckinReminder:  ChannelEmail,
+	NotifyLeaveDecision:    ChannelEmail,
+	NotifyWeeklySummary:    ChannelEmail,
+	NotifyDailyDigest:      ChannelEmail,
+	NotifyWeeklyDigest:     ChannelEmail,
+}
+
+// NotificationPreferences adalah pengaturan notifikasi milik user: channel per event
+type NotificationPreferences struct {
+	Events map[string]string `json:"events"`
+
+	// Email adalah pengaturan lama sebelum ada channel per event; false berarti semua email dimatikan
+	Email *bool `json:"email,omitempty"`
+}
+
+// Channel mengembalikan channel untuk event, memakai default jika user belum memilih
+func (p NotificationPreferences) Channel(event string) string {
+	channel, ok := p.Events[event]
+	if !ok {
+		channel = NotifyEvents[event]
+	}
+	if channel == ChannelEmail && p.Email != nil && !*p.Email {
+		return ChannelNone
+	}
+	if channel == "" {
+		return ChannelNone
+	}
+	return channel
+}
+
+// Resolved mengembalikan pengaturan dengan channel efektif untuk setiap event
+func (p NotificationPreferences) Resolved() NotificationPreferences {
+	resolved := NotificationPreferences{Events: map[string]string{}}
+	for event := range NotifyEvents {
+		resolved.Events[event] = p.Channel(event)
+	}
+	return resolved
+}
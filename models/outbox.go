@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// Status pesan di notification_outbox
+const (
+	OutboxStatusPending = "pending"
+	OutboxStatusSending = "sending"
+	OutboxStatusSent    = "sent"
+	OutboxStatusDead    = "dead" // Gagal setelah max_attempts, menunggu re-drive admin
+)
+
+// Channel pengiriman notifikasi
+const (
+	ChannelEmail = "email"
+	ChannelPush  = "push"
+	ChannelNone  = "none"
+)
+
+// PasswordTokenPlaceholder ditulis di pesan outbox sebagai pengganti token set password.
+// Worker mengganti placeholder ini dengan token baru saat pesan dikirim.
+const PasswordTokenPlaceholder = "__PASSWORD_TOKEN__"
+
+// OutboxMessage adalah satu notifikasi yang menunggu atau sudah dikirim worker
+type OutboxMessage struct {
+	ID            string     `json:"id"`
+	Channel       string     `json:"channel"`
+	Recipient     string     `json:"recipient"`
+	Subject       string     `json:"subject"`
+	Body          string     `json:"body"`
+	HTML          string     `json:"html,omitempty"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	MaxAttempts   int        `json:"max_attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	LastError     string     `json:"last_error,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+
+	// User yang dibuatkan token set password saat pesan dikirim, nil untuk pesan biasa
+	PasswordTokenUserID *string `json:"-"`
+}
+
+// OutboxPage adalah satu halaman hasil query outbox
+type OutboxPage struct {
+	Data     []OutboxMessage `json:"data"`
+	Total    int             `json:"total"`
+	Page     int             `json:"page"`
+	PageSize int             `json:"page_size"`
+}
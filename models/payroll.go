@@ -0,0 +1,26 @@
+package models
+
+// PayrollEntry adalah ringkasan kehadiran satu karyawan untuk satu periode payroll
+type PayrollEntry struct {
+	UserID                string  `json:"user_id"`
+	Name                  string  `json:"name"`
+	DepartmentName        string  `json:"department_name,omitempty"`
+	WorkingDays           int     `json:"working_days"`
+	DaysPresent           int     `json:"days_present"`
+	LateDays              int     `json:"late_days"`
+	LateMinutes           int     `json:"late_minutes"`
+	DeductibleLateMinutes int     `json:"deductible_late_minutes"`
+	UnpaidAbsences        int     `json:"unpaid_absences"`
+	PaidLeave             int     `json:"paid_leave"`
+	UnpaidLeave           int     `json:"unpaid_leave"`
+	WeekdayOvertimeHours  float64 `json:"weekday_overtime_hours"`
+	RestDayOvertimeHours  float64 `json:"rest_day_overtime_hours"`
+	OvertimePayHours      float64 `json:"overtime_pay_hours"`
+}
+
+// PayrollExport adalah hasil export payroll untuk satu periode
+type PayrollExport struct {
+	From    string         `json:"from"`
+	To      string         `json:"to"`
+	Entries []PayrollEntry `json:"entries"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Status permintaan penghapusan data pribadi
+const (
+	ErasureStatusPending   = "pending"
+	ErasureStatusScheduled = "scheduled" // Disetujui, menunggu masa retensi wajib selesai
+	ErasureStatusCompleted = "completed"
+	ErasureStatusRejected  = "rejected"
+)
+
+// AttendanceLogExport adalah satu log absensi pada ekspor data pribadi
+type AttendanceLogExport struct {
+	ID           string    `json:"id"`
+	AttendanceID string    `json:"attendance_id"`
+	Type         string    `json:"type"`
+	Source       string    `json:"source"`
+	LocationName string    `json:"location_name,omitempty"`
+	Notes        string    `json:"notes,omitempty"`
+	Latitude     *float64  `json:"latitude"`
+	Longitude    *float64  `json:"longitude"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// PersonalDataExport adalah seluruh data yang disimpan tentang satu karyawan
+type PersonalDataExport struct {
+	GeneratedAt         time.Time             `json:"generated_at"`
+	Profile             User                  `json:"profile"`
+	Attendance          []Attendance          `json:"attendance"`
+	AttendanceLogs      []AttendanceLogExport `json:"attendance_logs"`
+	LeaveRequests       []LeaveRequest        `json:"leave_requests"`
+	FingerprintMappings []FingerprintMapping  `json:"fingerprint_mappings"`
+	AuditLogs           []AuditLog            `json:"audit_logs"`
+	ErasureRequests     []ErasureRequest      `json:"erasure_requests"`
+}
+
+// ErasureRequest adalah permintaan penghapusan data pribadi oleh karyawan
+type ErasureRequest struct {
+	ID           string     `json:"id"`
+	UserID       string     `json:"user_id"`
+	Reason       string     `json:"reason,omitempty"`
+	Status       string     `json:"status"`
+	Note         string     `json:"note,omitempty"`
+	ReviewedBy   *string    `json:"reviewed_by,omitempty"`
+	ReviewedAt   *time.Time `json:"reviewed_at,omitempty"`
+	ScheduledFor *time.Time `json:"scheduled_for,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Pemicu job retensi
+const (
+	RetentionTriggerSchedule = "schedule"
+	RetentionTriggerManual   = "manual"
+)
+
+// RetentionRun adalah laporan satu putaran job retensi lokasi
+type RetentionRun struct {
+	ID                  string     `json:"id,omitempty"`
+	Trigger             string     `json:"trigger"` // schedule atau manual
+	DryRun              bool       `json:"dry_run"`
+	CoarsenBefore       *time.Time `json:"coarsen_before,omitempty"`
+	DeleteBefore        *time.Time `json:"delete_before,omitempty"`
+	CoarsenDecimals     int        `json:"coarsen_decimals"`
+	LogsCoarsened       int64      `json:"logs_coarsened"`
+	AttendanceCoarsened int64      `json:"attendance_coarsened"`
+	LogsCleared         int64      `json:"logs_cleared"`
+	AttendanceCleared   int64      `json:"attendance_cleared"`
+	StartedAt           time.Time  `json:"started_at"`
+	FinishedAt          time.Time  `json:"finished_at"`
+}
@@ -0,0 +1,25 @@
+package models
+
+// UserImportRow adalah hasil validasi satu baris CSV import user
+type UserImportRow struct {
+	Line           int      `json:"line"`
+	Name           string   `json:"name"`
+	Email          string   `json:"email"`
+	Role           string   `json:"role"`
+	Department     string   `json:"department,omitempty"`
+	Site           string   `json:"site,omitempty"`
+	EmployeeNumber string   `json:"employee_number,omitempty"`
+	UserID         string   `json:"user_id,omitempty"`
+	InviteQueued   bool     `json:"invite_queued,omitempty"`
+	Errors         []string `json:"errors,omitempty"`
+}
+
+// UserImportReport adalah ringkasan import user dari CSV
+type UserImportReport struct {
+	DryRun  bool            `json:"dry_run"`
+	Total   int             `json:"total"`
+	Valid   int             `json:"valid"`
+	Invalid int             `json:"invalid"`
+	Created int             `json:"created"`
+	Rows    []UserImportRow `json:"rows"`
+}
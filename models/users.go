@@ -2,12 +2,49 @@ package models
 
 import "time"
 
+// Role yang dikenal sistem
+const (
+	RoleAdmin    = "admin"
+	RoleManager  = "manager"
+	RoleEmployee = "employee"
+)
+
+// ValidRole mengecek apakah role dikenal sistem
+func ValidRole(role string) bool {
+	return role == RoleAdmin || role == RoleManager || role == RoleEmployee
+}
+
+// Status akun user
+const (
+	UserStatusActive   = "active"
+	UserStatusInactive = "inactive"
+)
+
 // User model for users table
 type User struct {
-    ID        string      `json:"id"`
-    Name      string    `json:"name"`
-    Email     string    `json:"email"`
-    Password  string    `json:"password"`
-    Role      string    `json:"role"`
-    CreatedAt time.Time `json:"created_at"`
-}
\ No newline at end of file
+	ID                      string                   `json:"id"`
+	Name                    string                   `json:"name"`
+	Email                   string                   `json:"email"`
+	Password                string                   `json:"password,omitempty"` // Hanya untuk input, hash tidak pernah dikirim ke client
+	Role                    string                   `json:"role"`
+	DepartmentID            *string                  `json:"department_id,omitempty"`
+	TeamID                  *string                  `json:"team_id,omitempty"`
+	ManagerID               *string                  `json:"manager_id,omitempty"`
+	EmployeeNumber          string                   `json:"employee_number,omitempty"`
+	Site                    string                   `json:"site,omitempty"`
+	Status                  string                   `json:"status,omitempty"`
+	Phone                   string                   `json:"phone,omitempty"`
+	AvatarURL               string                   `json:"avatar_url,omitempty"`
+	Language                string                   `json:"language,omitempty"` // Bahasa notifikasi: id atau en
+	NotificationPreferences *NotificationPreferences `json:"notification_preferences,omitempty"`
+	CreatedAt               time.Time                `json:"created_at"`
+	DeletedAt               *time.Time               `json:"deleted_at,omitempty"`
+}
+
+// UserPage adalah satu halaman hasil pencarian user
+type UserPage struct {
+	Data     []User `json:"data"`
+	Total    int    `json:"total"`
+	Page     int    `json:"page"`
+	PageSize int    `json:"page_size"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// VisitStop adalah satu titik perjalanan karyawan dalam satu hari
+type VisitStop struct {
+	ID               string    `json:"id"`
+	Type             string    `json:"type"`
+	LocationName     string    `json:"location_name,omitempty"`
+	Notes            string    `json:"notes,omitempty"`
+	Latitude         float64   `json:"latitude"`
+	Longitude        float64   `json:"longitude"`
+	ArrivedAt        time.Time `json:"arrived_at"`
+	DistanceFromPrev float64   `json:"distance_from_prev_m"`
+}
+
+// VisitRoute adalah rute kunjungan karyawan dalam satu hari
+type VisitRoute struct {
+	UserID        string      `json:"user_id"`
+	Date          string      `json:"date"`
+	Stops         []VisitStop `json:"stops"`
+	TotalDistance float64     `json:"total_distance_m"`
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Jenis event webhook keluar
+const (
+	WebhookAttendanceCheckedIn  = "attendance.checked_in"
+	WebhookAttendanceCheckedOut = "attendance.checked_out"
+	WebhookAttendanceVisit      = "attendance.visit"
+	WebhookLeaveApproved        = "leave.approved"
+	WebhookLeaveRejected        = "leave.rejected"
+	WebhookUserCreated          = "user.created"
+	WebhookTest                 = "webhook.test"
+)
+
+// WebhookEventTypes adalah event yang bisa dipilih saat mendaftarkan webhook
+var WebhookEventTypes = []string{
+	WebhookAttendanceCheckedIn,
+	WebhookAttendanceCheckedOut,
+	WebhookAttendanceVisit,
+	WebhookLeaveApproved,
+	WebhookLeaveRejected,
+	WebhookUserCreated,
+}
+
+// Status pengiriman webhook
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySending   = "sending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryFailed    = "failed" // Gagal setelah max_attempts
+)
+
+// WebhookEndpoint adalah URL milik sistem lain yang menerima event
+type WebhookEndpoint struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`
+	Description string    `json:"description,omitempty"`
+	EventTypes  []string  `json:"event_types"`
+	Active      bool      `json:"active"`
+	Secret      string    `json:"secret,omitempty"` // Hanya dikirim saat dibuat atau di-rotate
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// WebhookPayload adalah body JSON yang dikirim ke endpoint
+type WebhookPayload struct {
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// WebhookDelivery adalah log satu pengiriman event ke satu endpoint
+type WebhookDelivery struct {
+	ID             string          `json:"id"`
+	EndpointID     string          `json:"endpoint_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	ResponseStatus *int            `json:"response_status,omitempty"`
+	ResponseBody   string          `json:"response_body,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+}
+
+// WebhookDeliveryPage adalah satu halaman log pengiriman webhook
+type WebhookDeliveryPage struct {
+	Data     []WebhookDelivery `json:"data"`
+	Total    int               `json:"total"`
+	Page     int               `json:"page"`
+	PageSize int               `json:"page_size"`
+}
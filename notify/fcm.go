@@ -0,0 +1,141 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const fcmScope = "https://www.googleapis.com/auth/firebase.messaging"
+
+// ErrInvalidToken dikembalikan jika token perangkat sudah tidak berlaku dan sebaiknya dihapus
+var ErrInvalidToken = errors.New("device token is no longer valid")
+
+// PushNotifier mengirim push notification ke satu token perangkat
+type PushNotifier interface {
+	SendPush(ctx context.Context, token string, msg Message) error
+}
+
+// Push adalah push notifier yang dipakai worker outbox, nil jika push tidak dikonfigurasi
+var Push PushNotifier
+
+// FCMNotifier mengirim push lewat Firebase Cloud Messaging HTTP v1 API
+type FCMNotifier struct {
+	endpoint string
+	project  string
+	client   *http.Client
+}
+
+// NewFCMNotifier membuat notifier FCM. endpoint adalah base URL API (default https://fcm.googleapis.com/v1),
+// client harus sudah menyertakan autentikasi OAuth2 kecuali untuk server FCM palsu saat pengujian.
+func NewFCMNotifier(endpoint, project string, client *http.Client) *FCMNotifier {
+	return &FCMNotifier{endpoint: strings.TrimRight(endpoint, "/"), project: project, client: client}
+}
+
+// SendPush mengirim notifikasi dengan judul Subject dan isi Text
+func (f *FCMNotifier) SendPush(ctx context.Context, token string, msg Message) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"message": map[string]interface{}{
+			"token":        token,
+			"notification": map[string]string{"title": msg.Subject, "body": msg.Text},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/projects/%s/messages:send", f.endpoint, f.project)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var body struct {
+		Error struct {
+			Status  string `json:"status"`
+			Message string `json:"message"`
+			Details []struct {
+				ErrorCode string `json:"errorCode"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	json.Unmarshal(raw, &body)
+
+	// Hanya token yang sudah tidak terdaftar (aplikasi di-uninstall atau token kedaluwarsa) yang dihapus.
+	// INVALID_ARGUMENT juga dipakai FCM untuk payload yang salah, jadi dianggap error biasa dan di-retry outbox.
+	for _, d := range body.Error.Details {
+		if d.ErrorCode == "UNREGISTERED" {
+			return ErrInvalidToken
+		}
+	}
+	if resp.StatusCode == http.StatusNotFound && body.Error.Status == "NOT_FOUND" {
+		return ErrInvalidToken
+	}
+	return fmt.Errorf("fcm returned %d: %s", resp.StatusCode, body.Error.Message)
+}
+
+// PushFromEnv membuat notifier FCM dari env. FIREBASE_CREDENTIALS berisi path service account;
+// FCM_PROJECT_ID default diambil dari service account. FCM_ENDPOINT bisa diarahkan ke server FCM
+// palsu di lokal, dan jika FIREBASE_CREDENTIALS kosong request dikirim tanpa autentikasi.
+// Mengembalikan nil jika push tidak dikonfigurasi.
+func PushFromEnv(ctx context.Context) (PushNotifier, error) {
+	endpoint := os.Getenv("FCM_ENDPOINT")
+	credsPath := os.Getenv("FIREBASE_CREDENTIALS")
+	project := os.Getenv("FCM_PROJECT_ID")
+
+	if credsPath == "" {
+		if endpoint == "" {
+			return nil, nil
+		}
+		if project == "" {
+			project = "local"
+		}
+		return NewFCMNotifier(endpoint, project, &http.Client{Timeout: 10 * time.Second}), nil
+	}
+
+	data, err := os.ReadFile(credsPath)
+	if err != nil {
+		return nil, err
+	}
+	creds, err := google.CredentialsFromJSON(ctx, data, fcmScope)
+	if err != nil {
+		return nil, err
+	}
+	if project == "" {
+		project = creds.ProjectID
+	}
+	if project == "" {
+		return nil, fmt.Errorf("FCM_PROJECT_ID is not set")
+	}
+	if endpoint == "" {
+		endpoint = "https://fcm.googleapis.com/v1"
+	}
+
+	client := &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: &oauth2.Transport{Source: creds.TokenSource, Base: http.DefaultTransport},
+	}
+	return NewFCMNotifier(endpoint, project, client), nil
+}
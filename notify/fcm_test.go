@@ -0,0 +1,103 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFCMNotifierSendPush(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		wantErr     bool
+		wantInvalid bool
+	}{
+		{
+			name:   "success",
+			status: http.StatusOK,
+			body:   `{"name": "projects/test/messages/1"}`,
+		},
+		{
+			name:        "unregistered token is pruned",
+			status:      http.StatusNotFound,
+			body:        `{"error": {"code": 404, "status": "NOT_FOUND", "message": "Requested entity was not found.", "details": [{"errorCode": "UNREGISTERED"}]}}`,
+			wantErr:     true,
+			wantInvalid: true,
+		},
+		{
+			name:        "not found without details is pruned",
+			status:      http.StatusNotFound,
+			body:        `{"error": {"code": 404, "status": "NOT_FOUND", "message": "Requested entity was not found."}}`,
+			wantErr:     true,
+			wantInvalid: true,
+		},
+		{
+			name:    "invalid argument keeps the token",
+			status:  http.StatusBadRequest,
+			body:    `{"error": {"code": 400, "status": "INVALID_ARGUMENT", "message": "Invalid JSON payload", "details": [{"errorCode": "INVALID_ARGUMENT"}]}}`,
+			wantErr: true,
+		},
+		{
+			name:    "unavailable is retryable",
+			status:  http.StatusServiceUnavailable,
+			body:    `{"error": {"code": 503, "status": "UNAVAILABLE", "message": "The service is currently unavailable."}}`,
+			wantErr: true,
+		},
+		{
+			name:    "quota exceeded is retryable",
+			status:  http.StatusTooManyRequests,
+			body:    `{"error": {"code": 429, "status": "RESOURCE_EXHAUSTED", "message": "Quota exceeded", "details": [{"errorCode": "QUOTA_EXCEEDED"}]}}`,
+			wantErr: true,
+		},
+		{
+			name:    "non json error body",
+			status:  http.StatusBadGateway,
+			body:    `<html>Bad Gateway</html>`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got struct {
+				Message struct {
+					Token        string            `json:"token"`
+					Notification map[string]string `json:"notification"`
+				} `json:"message"`
+			}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != "/v1/projects/test/messages:send" {
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+				}
+				if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+					t.Errorf("decode request: %v", err)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			fcm := NewFCMNotifier(server.URL+"/v1/", "test", server.Client())
+			err := fcm.SendPush(context.Background(), "device-token", Message{Subject: "Check-in", Text: "Check-in berhasil"})
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SendPush() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrInvalidToken) != tt.wantInvalid {
+				t.Fatalf("SendPush() error = %v, want ErrInvalidToken %v", err, tt.wantInvalid)
+			}
+			if got.Message.Token != "device-token" {
+				t.Errorf("token = %q, want %q", got.Message.Token, "device-token")
+			}
+			if got.Message.Notification["title"] != "Check-in" || got.Message.Notification["body"] != "Check-in berhasil" {
+				t.Errorf("notification = %v", got.Message.Notification)
+			}
+		})
+	}
+}
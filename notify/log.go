@@ -0,0 +1,51 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// LogNotifier tidak mengirim apa pun, hanya mencatat pesan ke log server atau ke file JSON lines.
+// Dipakai untuk development lokal dan pengujian tanpa koneksi internet. Log server hanya berisi
+// penerima dan subjek karena isi pesan bisa memuat link rahasia; isi lengkap hanya ditulis ke file.
+type LogNotifier struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewLogNotifier membuat notifier log. Path kosong berarti menulis ke log server.
+func NewLogNotifier(path string) *LogNotifier {
+	return &LogNotifier{path: path}
+}
+
+// Send mencatat pesan
+func (l *LogNotifier) Send(ctx context.Context, msg Message) error {
+	if l.path == "" {
+		log.Printf("[notify] to=%s subject=%q", msg.To, msg.Subject)
+		return nil
+	}
+
+	line, err := json.Marshal(struct {
+		Message
+		SentAt time.Time `json:"sent_at"`
+	}{msg, time.Now()})
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,94 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Message adalah satu notifikasi yang akan dikirim ke penerima
+type Message struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+	HTML    string `json:"html,omitempty"`
+}
+
+// Notifier mengirim notifikasi lewat satu provider
+type Notifier interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Default adalah notifier yang dipakai worker outbox, diganti di main sesuai konfigurasi
+var Default Notifier = NewLogNotifier("")
+
+// Sender adalah alamat pengirim dari env EMAIL_FROM dan EMAIL_FROM_NAME
+type Sender struct {
+	Name    string
+	Address string
+}
+
+func senderFromEnv() (Sender, error) {
+	sender := Sender{Name: os.Getenv("EMAIL_FROM_NAME"), Address: os.Getenv("EMAIL_FROM")}
+	if sender.Name == "" {
+		sender.Name = "Absensi App"
+	}
+	if sender.Address == "" {
+		return sender, fmt.Errorf("EMAIL_FROM is not set")
+	}
+	return sender, nil
+}
+
+// FromEnv membuat notifier sesuai env NOTIFIER: sendgrid, smtp, log atau webhook.
+// Jika NOTIFIER kosong, sendgrid dipakai bila SENDGRID_API_KEY diisi; selain itu konfigurasi dianggap
+// salah agar server produksi tidak diam-diam hanya menulis email ke log. Notifier log harus dipilih eksplisit.
+func FromEnv() (Notifier, error) {
+	kind := strings.ToLower(os.Getenv("NOTIFIER"))
+	if kind == "" {
+		if os.Getenv("SENDGRID_API_KEY") == "" {
+			return nil, fmt.Errorf("NOTIFIER is not set (use sendgrid, smtp, log or webhook)")
+		}
+		kind = "sendgrid"
+	}
+
+	switch kind {
+	case "sendgrid":
+		sender, err := senderFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		apiKey := os.Getenv("SENDGRID_API_KEY")
+		if apiKey == "" {
+			return nil, fmt.Errorf("SENDGRID_API_KEY is not set")
+		}
+		return NewSendGridNotifier(apiKey, sender), nil
+
+	case "smtp":
+		sender, err := senderFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		host := os.Getenv("SMTP_HOST")
+		if host == "" {
+			return nil, fmt.Errorf("SMTP_HOST is not set")
+		}
+		port := 587
+		if p, err := strconv.Atoi(os.Getenv("SMTP_PORT")); err == nil && p > 0 {
+			port = p
+		}
+		return NewSMTPNotifier(host, port, os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"), sender), nil
+
+	case "log":
+		return NewLogNotifier(os.Getenv("NOTIFIER_LOG_FILE")), nil
+
+	case "webhook":
+		url := os.Getenv("NOTIFIER_WEBHOOK_URL")
+		if url == "" {
+			return nil, fmt.Errorf("NOTIFIER_WEBHOOK_URL is not set")
+		}
+		return NewWebhookNotifier(url, os.Getenv("NOTIFIER_WEBHOOK_TOKEN")), nil
+	}
+	return nil, fmt.Errorf("unknown NOTIFIER %q", kind)
+}
@@ -0,0 +1,43 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"html"
+
+	"github.com/sendgrid/sendgrid-go"
+	"github.com/sendgrid/sendgrid-go/helpers/mail"
+)
+
+// SendGridNotifier mengirim email lewat API SendGrid
+type SendGridNotifier struct {
+	apiKey string
+	sender Sender
+}
+
+// NewSendGridNotifier membuat notifier SendGrid
+func NewSendGridNotifier(apiKey string, sender Sender) *SendGridNotifier {
+	return &SendGridNotifier{apiKey: apiKey, sender: sender}
+}
+
+// Send mengirim email; jika HTML kosong, teks biasa dipakai sebagai isi HTML
+func (s *SendGridNotifier) Send(ctx context.Context, msg Message) error {
+	htmlBody := msg.HTML
+	if htmlBody == "" {
+		htmlBody = html.EscapeString(msg.Text)
+	}
+
+	from := mail.NewEmail(s.sender.Name, s.sender.Address)
+	to := mail.NewEmail("", msg.To)
+	message := mail.NewSingleEmail(from, msg.Subject, to, msg.Text, htmlBody)
+
+	client := sendgrid.NewSendClient(s.apiKey)
+	response, err := client.SendWithContext(ctx, message)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode >= 400 {
+		return fmt.Errorf("failed to send email: %s", response.Body)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"time"
+)
+
+// SMTPNotifier mengirim email lewat server SMTP biasa (STARTTLS otomatis bila didukung server)
+type SMTPNotifier struct {
+	host     string
+	port     int
+	username string
+	password string
+	sender   Sender
+}
+
+// NewSMTPNotifier membuat notifier SMTP. Username kosong berarti tanpa autentikasi.
+func NewSMTPNotifier(host string, port int, username, password string, sender Sender) *SMTPNotifier {
+	return &SMTPNotifier{host: host, port: port, username: username, password: password, sender: sender}
+}
+
+// buildMIME menyusun email multipart/alternative berisi teks dan HTML (jika ada)
+func buildMIME(from Sender, msg Message) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	header := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\nMIME-Version: 1.0\r\nContent-Type: multipart/alternative; boundary=%s\r\n\r\n",
+		mime.QEncoding.Encode("utf-8", from.Name)+" <"+from.Address+">", msg.To,
+		mime.QEncoding.Encode("utf-8", msg.Subject), time.Now().Format(time.RFC1123Z), writer.Boundary())
+
+	parts := []struct{ contentType, body string }{{"text/plain", msg.Text}}
+	if msg.HTML != "" {
+		parts = append(parts, struct{ contentType, body string }{"text/html", msg.HTML})
+	}
+	for _, p := range parts {
+		part, err := writer.CreatePart(textproto.MIMEHeader{"Content-Type": {p.contentType + "; charset=utf-8"}})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := part.Write([]byte(p.body)); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return append([]byte(header), buf.Bytes()...), nil
+}
+
+// smtpTimeout membatasi seluruh percakapan SMTP jika ctx tidak punya deadline sendiri
+const smtpTimeout = 30 * time.Second
+
+// Send mengirim email lewat SMTP. Koneksi dibuka dengan ctx dan diberi deadline sehingga server
+// yang lambat atau tidak merespons tidak menahan worker outbox.
+func (s *SMTPNotifier) Send(ctx context.Context, msg Message) error {
+	body, err := buildMIME(s.sender, msg)
+	if err != nil {
+		return err
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(smtpTimeout)
+	}
+	dialer := net.Dialer{Deadline: deadline}
+	addr := net.JoinHostPort(s.host, strconv.Itoa(s.port))
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+
+	// Tutup koneksi jika ctx dibatalkan di tengah percakapan
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	client, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: s.host}); err != nil {
+			return err
+		}
+	}
+	if s.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.username, s.password, s.host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(s.sender.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
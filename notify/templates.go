@@ -0,0 +1,354 @@
+package notify
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"sort"
+	"text/template"
+)
+
+// Bahasa yang didukung
+const (
+	LangID = "id"
+	LangEN = "en"
+)
+
+// Kunci template notifikasi
+const (
+	TemplateCheckIn  = "check_in"
+	TemplateCheckOut = "check_out"
+	TemplateInvite   = "invite"
+
+	TemplateLeaveDecision = "leave_decision"
+
+	TemplateCheckoutReminder = "checkout_reminder"
+	TemplateCheckinReminder  = "checkin_reminder"
+
+	TemplateManagerDigest = "manager_digest"
+	TemplateWeeklySummary = "weekly_summary"
+)
+
+// Languages adalah daftar bahasa yang didukung, bahasa pertama adalah default
+var Languages = []string{LangID, LangEN}
+
+// NormalizeLanguage mengembalikan lang jika didukung, selain itu bahasa default
+func NormalizeLanguage(lang string) string {
+	for _, l := range Languages {
+		if l == lang {
+			return lang
+		}
+	}
+	return LangID
+}
+
+// Template adalah template notifikasi dalam satu bahasa. Subject dan Text memakai text/template,
+// HTML memakai html/template sehingga data otomatis di-escape.
+type Template struct {
+	Key      string `json:"key"`
+	Language string `json:"language"`
+	Subject  string `json:"subject"`
+	Text     string `json:"text"`
+	HTML     string `json:"html"`
+}
+
+// defaultTemplates adalah template bawaan, admin dapat menimpanya lewat notification_templates
+var defaultTemplates = map[string]map[string]Template{
+	TemplateCheckIn: {
+		LangID: {
+			Subject: "Check-in Berhasil",
+			Text: `Halo {{.Name}}, Anda berhasil check-in pada {{.Date}} pukul {{.Time}}{{if .Site}} di {{.Site}}{{end}}.
+{{- if .Late}} Anda terlambat {{.LateMinutes}} menit.{{else}} Tepat waktu, terima kasih!{{end}}`,
+			HTML: `<p>Halo {{.Name}},</p>
+<p>Anda berhasil check-in pada <strong>{{.Date}}</strong> pukul <strong>{{.Time}}</strong>{{if .Site}} di {{.Site}}{{end}}.</p>
+{{if .Late}}<p style="color:#b91c1c">Anda terlambat {{.LateMinutes}} menit.</p>{{else}}<p style="color:#15803d">Tepat waktu, terima kasih!</p>{{end}}`,
+		},
+		LangEN: {
+			Subject: "Check-in Successful",
+			Text: `Hi {{.Name}}, you checked in on {{.Date}} at {{.Time}}{{if .Site}} at {{.Site}}{{end}}.
+{{- if .Late}} You are {{.LateMinutes}} minutes late.{{else}} On time, thank you!{{end}}`,
+			HTML: `<p>Hi {{.Name}},</p>
+<p>You checked in on <strong>{{.Date}}</strong> at <strong>{{.Time}}</strong>{{if .Site}} at {{.Site}}{{end}}.</p>
+{{if .Late}}<p style="color:#b91c1c">You are {{.LateMinutes}} minutes late.</p>{{else}}<p style="color:#15803d">On time, thank you!</p>{{end}}`,
+		},
+	},
+	TemplateCheckOut: {
+		LangID: {
+			Subject: "Check-out Berhasil",
+			Text:    `Halo {{.Name}}, Anda berhasil check-out pada {{.Date}} pukul {{.Time}}{{if .Site}} di {{.Site}}{{end}}.{{if .WorkedHours}} Total jam kerja hari ini: {{.WorkedHours}} jam.{{end}}`,
+			HTML: `<p>Halo {{.Name}},</p>
+<p>Anda berhasil check-out pada <strong>{{.Date}}</strong> pukul <strong>{{.Time}}</strong>{{if .Site}} di {{.Site}}{{end}}.</p>
+{{if .WorkedHours}}<p>Total jam kerja hari ini: {{.WorkedHours}} jam.</p>{{end}}`,
+		},
+		LangEN: {
+			Subject: "Check-out Successful",
+			Text:    `Hi {{.Name}}, you checked out on {{.Date}} at {{.Time}}{{if .Site}} at {{.Site}}{{end}}.{{if .WorkedHours}} Hours worked today: {{.WorkedHours}}.{{end}}`,
+			HTML: `<p>Hi {{.Name}},</p>
+<p>You checked out on <strong>{{.Date}}</strong> at <strong>{{.Time}}</strong>{{if .Site}} at {{.Site}}{{end}}.</p>
+{{if .WorkedHours}}<p>Hours worked today: {{.WorkedHours}}.</p>{{end}}`,
+		},
+	},
+	TemplateInvite: {
+		LangID: {
+			Subject: "Undangan Absensi App",
+			Text:    `Halo {{.Name}}, akun absensi Anda sudah dibuat. Silakan atur password melalui link berikut (berlaku {{.ValidHours}} jam): {{.Link}}`,
+			HTML: `<p>Halo {{.Name}},</p>
+<p>Akun absensi Anda sudah dibuat. Silakan atur password melalui link berikut (berlaku {{.ValidHours}} jam):</p>
+<p><a href="{{.Link}}">Atur password</a></p>`,
+		},
+		LangEN: {
+			Subject: "Absensi App Invitation",
+			Text:    `Hi {{.Name}}, your attendance account has been created. Please set your password using this link (valid for {{.ValidHours}} hours): {{.Link}}`,
+			HTML: `<p>Hi {{.Name}},</p>
+<p>Your attendance account has been created. Please set your password using this link (valid for {{.ValidHours}} hours):</p>
+<p><a href="{{.Link}}">Set password</a></p>`,
+		},
+	},
+	TemplateLeaveDecision: {
+		LangID: {
+			Subject: `Pengajuan Cuti {{if .Approved}}Disetujui{{else}}Ditolak{{end}}`,
+			Text:    `Halo {{.Name}}, pengajuan cuti {{.Type}} Anda untuk {{.StartDate}} s/d {{.EndDate}} telah {{if .Approved}}disetujui{{else}}ditolak{{end}} oleh {{.Reviewer}}.`,
+			HTML: `<p>Halo {{.Name}},</p>
+<p>Pengajuan cuti <strong>{{.Type}}</strong> Anda untuk {{.StartDate}} s/d {{.EndDate}} telah
+{{if .Approved}}<strong style="color:#15803d">disetujui</strong>{{else}}<strong style="color:#b91c1c">ditolak</strong>{{end}} oleh {{.Reviewer}}.</p>`,
+		},
+		LangEN: {
+			Subject: `Leave Request {{if .Approved}}Approved{{else}}Rejected{{end}}`,
+			Text:    `Hi {{.Name}}, your {{.Type}} leave request for {{.StartDate}} to {{.EndDate}} has been {{if .Approved}}approved{{else}}rejected{{end}} by {{.Reviewer}}.`,
+			HTML: `<p>Hi {{.Name}},</p>
+<p>Your <strong>{{.Type}}</strong> leave request for {{.StartDate}} to {{.EndDate}} has been
+{{if .Approved}}<strong style="color:#15803d">approved</strong>{{else}}<strong style="color:#b91c1c">rejected</strong>{{end}} by {{.Reviewer}}.</p>`,
+		},
+	},
+	TemplateCheckoutReminder: {
+		LangID: {
+			Subject: "Jangan Lupa Check-out",
+			Text:    `Halo {{.Name}}, Anda check-in pukul {{.CheckInTime}} dan belum check-out, padahal jam kerja sudah selesai pukul {{.ShiftEnd}}. Silakan check-out jika sudah selesai bekerja.`,
+			HTML: `<p>Halo {{.Name}},</p>
+<p>Anda check-in pukul <strong>{{.CheckInTime}}</strong> dan belum check-out, padahal jam kerja sudah selesai pukul <strong>{{.ShiftEnd}}</strong>.</p>
+<p>Silakan check-out jika sudah selesai bekerja.</p>`,
+		},
+		LangEN: {
+			Subject: "Don't Forget to Check Out",
+			Text:    `Hi {{.Name}}, you checked in at {{.CheckInTime}} and have not checked out, although your shift ended at {{.ShiftEnd}}. Please check out if you have finished working.`,
+			HTML: `<p>Hi {{.Name}},</p>
+<p>You checked in at <strong>{{.CheckInTime}}</strong> and have not checked out, although your shift ended at <strong>{{.ShiftEnd}}</strong>.</p>
+<p>Please check out if you have finished working.</p>`,
+		},
+	},
+	TemplateCheckinReminder: {
+		LangID: {
+			Subject: "Anda Belum Check-in",
+			Text:    `Halo {{.Name}}, jam kerja dimulai pukul {{.ShiftStart}} dan Anda belum check-in hari ini ({{.Date}}). Silakan check-in sekarang atau ajukan cuti jika tidak masuk.`,
+			HTML: `<p>Halo {{.Name}},</p>
+<p>Jam kerja dimulai pukul <strong>{{.ShiftStart}}</strong> dan Anda belum check-in hari ini ({{.Date}}).</p>
+<p>Silakan check-in sekarang atau ajukan cuti jika tidak masuk.</p>`,
+		},
+		LangEN: {
+			Subject: "You Have Not Checked In",
+			Text:    `Hi {{.Name}}, your shift started at {{.ShiftStart}} and you have not checked in today ({{.Date}}). Please check in now or submit a leave request if you are not working.`,
+			HTML: `<p>Hi {{.Name}},</p>
+<p>Your shift started at <strong>{{.ShiftStart}}</strong> and you have not checked in today ({{.Date}}).</p>
+<p>Please check in now or submit a leave request if you are not working.</p>`,
+		},
+	},
+	TemplateManagerDigest: {
+		LangID: {
+			Subject: `Ringkasan {{if .Weekly}}Mingguan{{else}}Harian{{end}} Tim: {{.Period}}`,
+			Text: `Halo {{.Name}}, berikut ringkasan kehadiran {{.TeamSize}} anggota tim Anda untuk {{.Period}}.
+{{- if .Late}}
+
+Terlambat:{{range .Late}}
+- {{.Name}} ({{.Date}}): {{.LateMinutes}} menit{{end}}{{end}}
+{{- if .Absent}}
+
+Tidak hadir:{{range .Absent}}
+- {{.Name}} ({{.Date}}){{end}}{{end}}
+{{- if .Overtime}}
+
+Lembur:{{range .Overtime}}
+- {{.Name}}: {{.Hours}} jam{{end}}{{end}}
+{{- if .PendingLeave}}
+
+Cuti menunggu persetujuan:{{range .PendingLeave}}
+- {{.Name}}: {{.Type}}, {{.StartDate}} s/d {{.EndDate}}{{end}}{{end}}
+{{- if not .HasItems}}
+
+Tidak ada keterlambatan, ketidakhadiran, lembur, maupun cuti yang menunggu persetujuan.{{end}}`,
+			HTML: `<p>Halo {{.Name}},</p>
+<p>Berikut ringkasan kehadiran {{.TeamSize}} anggota tim Anda untuk <strong>{{.Period}}</strong>.</p>
+{{if .Late}}<h3>Terlambat</h3><ul>{{range .Late}}<li>{{.Name}} ({{.Date}}): {{.LateMinutes}} menit</li>{{end}}</ul>{{end}}
+{{if .Absent}}<h3>Tidak hadir</h3><ul>{{range .Absent}}<li>{{.Name}} ({{.Date}})</li>{{end}}</ul>{{end}}
+{{if .Overtime}}<h3>Lembur</h3><ul>{{range .Overtime}}<li>{{.Name}}: {{.Hours}} jam</li>{{end}}</ul>{{end}}
+{{if .PendingLeave}}<h3>Cuti menunggu persetujuan</h3><ul>{{range .PendingLeave}}<li>{{.Name}}: {{.Type}}, {{.StartDate}} s/d {{.EndDate}}</li>{{end}}</ul>{{end}}
+{{if not .HasItems}}<p style="color:#15803d">Tidak ada keterlambatan, ketidakhadiran, lembur, maupun cuti yang menunggu persetujuan.</p>{{end}}`,
+		},
+		LangEN: {
+			Subject: `Team {{if .Weekly}}Weekly{{else}}Daily{{end}} Digest: {{.Period}}`,
+			Text: `Hi {{.Name}}, here is the attendance summary of your {{.TeamSize}} team members for {{.Period}}.
+{{- if .Late}}
+
+Late arrivals:{{range .Late}}
+- {{.Name}} ({{.Date}}): {{.LateMinutes}} minutes{{end}}{{end}}
+{{- if .Absent}}
+
+Absences:{{range .Absent}}
+- {{.Name}} ({{.Date}}){{end}}{{end}}
+{{- if .Overtime}}
+
+Overtime:{{range .Overtime}}
+- {{.Name}}: {{.Hours}} hours{{end}}{{end}}
+{{- if .PendingLeave}}
+
+Leave awaiting approval:{{range .PendingLeave}}
+- {{.Name}}: {{.Type}}, {{.StartDate}} to {{.EndDate}}{{end}}{{end}}
+{{- if not .HasItems}}
+
+No late arrivals, absences, overtime or pending leave requests.{{end}}`,
+			HTML: `<p>Hi {{.Name}},</p>
+<p>Here is the attendance summary of your {{.TeamSize}} team members for <strong>{{.Period}}</strong>.</p>
+{{if .Late}}<h3>Late arrivals</h3><ul>{{range .Late}}<li>{{.Name}} ({{.Date}}): {{.LateMinutes}} minutes</li>{{end}}</ul>{{end}}
+{{if .Absent}}<h3>Absences</h3><ul>{{range .Absent}}<li>{{.Name}} ({{.Date}})</li>{{end}}</ul>{{end}}
+{{if .Overtime}}<h3>Overtime</h3><ul>{{range .Overtime}}<li>{{.Name}}: {{.Hours}} hours</li>{{end}}</ul>{{end}}
+{{if .PendingLeave}}<h3>Leave awaiting approval</h3><ul>{{range .PendingLeave}}<li>{{.Name}}: {{.Type}}, {{.StartDate}} to {{.EndDate}}</li>{{end}}</ul>{{end}}
+{{if not .HasItems}}<p style="color:#15803d">No late arrivals, absences, overtime or pending leave requests.</p>{{end}}`,
+		},
+	},
+	TemplateWeeklySummary: {
+		LangID: {
+			Subject: `Ringkasan Kehadiran Mingguan: {{.Period}}`,
+			Text: `Halo {{.Name}}, berikut ringkasan kehadiran Anda untuk {{.Period}}.
+
+Hadir: {{.Present}} hari
+Terlambat: {{.Late}} kali ({{.LateMinutes}} menit)
+Tidak hadir: {{.Absent}} hari
+Cuti: {{.Leave}} hari
+Jam kerja: {{.WorkedHours}} jam
+Lembur: {{.OvertimeHours}} jam`,
+			HTML: `<p>Halo {{.Name}},</p>
+<p>Berikut ringkasan kehadiran Anda untuk <strong>{{.Period}}</strong>.</p>
+<table>
+<tr><td>Hadir</td><td>{{.Present}} hari</td></tr>
+<tr><td>Terlambat</td><td>{{.Late}} kali ({{.LateMinutes}} menit)</td></tr>
+<tr><td>Tidak hadir</td><td>{{.Absent}} hari</td></tr>
+<tr><td>Cuti</td><td>{{.Leave}} hari</td></tr>
+<tr><td>Jam kerja</td><td>{{.WorkedHours}} jam</td></tr>
+<tr><td>Lembur</td><td>{{.OvertimeHours}} jam</td></tr>
+</table>`,
+		},
+		LangEN: {
+			Subject: `Weekly Attendance Summary: {{.Period}}`,
+			Text: `Hi {{.Name}}, here is your attendance summary for {{.Period}}.
+
+Present: {{.Present}} days
+Late: {{.Late}} times ({{.LateMinutes}} minutes)
+Absent: {{.Absent}} days
+Leave: {{.Leave}} days
+Worked: {{.WorkedHours}} hours
+Overtime: {{.OvertimeHours}} hours`,
+			HTML: `<p>Hi {{.Name}},</p>
+<p>Here is your attendance summary for <strong>{{.Period}}</strong>.</p>
+<table>
+<tr><td>Present</td><td>{{.Present}} days</td></tr>
+<tr><td>Late</td><td>{{.Late}} times ({{.LateMinutes}} minutes)</td></tr>
+<tr><td>Absent</td><td>{{.Absent}} days</td></tr>
+<tr><td>Leave</td><td>{{.Leave}} days</td></tr>
+<tr><td>Worked</td><td>{{.WorkedHours}} hours</td></tr>
+<tr><td>Overtime</td><td>{{.OvertimeHours}} hours</td></tr>
+</table>`,
+		},
+	},
+}
+
+// sampleData adalah contoh data untuk preview template di panel admin
+var sampleData = map[string]map[string]interface{}{
+	TemplateCheckIn: {
+		"Name": "Budi Santoso", "Date": "19-10-2026", "Time": "08:12", "Site": "Kantor Pusat Jakarta",
+		"Late": true, "LateMinutes": 12,
+	},
+	TemplateCheckOut: {
+		"Name": "Budi Santoso", "Date": "19-10-2026", "Time": "17:05", "Site": "Kantor Pusat Jakarta",
+		"WorkedHours": "8.9",
+	},
+	TemplateLeaveDecision: {
+		"Name": "Budi Santoso", "Type": "annual", "StartDate": "20-10-2026", "EndDate": "22-10-2026",
+		"Approved": true, "Reviewer": "Siti Rahma",
+	},
+	TemplateCheckoutReminder: {
+		"Name": "Budi Santoso", "Date": "19-10-2026", "CheckInTime": "08:02", "ShiftEnd": "17:00",
+	},
+	TemplateCheckinReminder: {
+		"Name": "Budi Santoso", "Date": "19-10-2026", "ShiftStart": "08:00",
+	},
+	TemplateManagerDigest: {
+		"Name": "Siti Rahma", "Weekly": false, "Period": "19-10-2026", "TeamSize": 8, "HasItems": true,
+		"Late":         []map[string]interface{}{{"Name": "Budi Santoso", "Date": "19-10-2026", "LateMinutes": 12}},
+		"Absent":       []map[string]interface{}{{"Name": "Andi Wijaya", "Date": "19-10-2026"}},
+		"Overtime":     []map[string]interface{}{{"Name": "Dewi Lestari", "Hours": 2.5}},
+		"PendingLeave": []map[string]interface{}{{"Name": "Rina Putri", "Type": "annual", "StartDate": "22-10-2026", "EndDate": "23-10-2026"}},
+	},
+	TemplateWeeklySummary: {
+		"Name": "Budi Santoso", "Period": "12-10-2026 - 18-10-2026", "Present": 4, "Late": 1, "LateMinutes": 12,
+		"Absent": 0, "Leave": 1, "WorkedHours": 35.5, "OvertimeHours": 1.5,
+	},
+	TemplateInvite: {
+		"Name": "Budi Santoso", "Link": "https://absensi.example.com/set-password?token=contoh", "ValidHours": 72,
+	},
+}
+
+// TemplateKeys mengembalikan semua kunci template bawaan secara terurut
+func TemplateKeys() []string {
+	keys := make([]string, 0, len(defaultTemplates))
+	for key := range defaultTemplates {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// DefaultTemplate mengembalikan template bawaan untuk key dan bahasa
+func DefaultTemplate(key, lang string) (Template, bool) {
+	t, ok := defaultTemplates[key][lang]
+	t.Key, t.Language = key, lang
+	return t, ok
+}
+
+// SampleData mengembalikan contoh data preview untuk key
+func SampleData(key string) map[string]interface{} {
+	return sampleData[key]
+}
+
+// Render mengisi template dengan data dan menghasilkan pesan untuk penerima to
+func (t Template) Render(to string, data interface{}) (Message, error) {
+	msg := Message{To: to}
+
+	execText := func(name, src string) (string, error) {
+		tmpl, err := template.New(name).Parse(src)
+		if err != nil {
+			return "", err
+		}
+		var buf bytes.Buffer
+		err = tmpl.Execute(&buf, data)
+		return buf.String(), err
+	}
+
+	var err error
+	if msg.Subject, err = execText("subject", t.Subject); err != nil {
+		return msg, err
+	}
+	if msg.Text, err = execText("text", t.Text); err != nil {
+		return msg, err
+	}
+
+	if t.HTML != "" {
+		tmpl, err := htmltemplate.New("html").Parse(t.HTML)
+		if err != nil {
+			return msg, err
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return msg, err
+		}
+		msg.HTML = buf.String()
+	}
+	return msg, nil
+}
@@ -0,0 +1,52 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// WebhookNotifier meneruskan pesan sebagai JSON ke URL HTTP, misalnya gateway notifikasi internal
+type WebhookNotifier struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewWebhookNotifier membuat notifier webhook. Token diisi di header Authorization: Bearer jika tidak kosong.
+func NewWebhookNotifier(url, token string) *WebhookNotifier {
+	return &WebhookNotifier{url: url, token: token, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Send mengirim POST berisi pesan; status selain 2xx dianggap gagal
+func (n *WebhookNotifier) Send(ctx context.Context, msg Message) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if n.token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.token)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook returned %d: %s", resp.StatusCode, body)
+	}
+	return nil
+}
@@ -1,21 +1,35 @@
 package routes
 
 import (
+	"net/http"
+
 	"absensi/controller"
 	"absensi/middleware" // Pastikan middleware diimpor
-	"github.com/gorilla/mux"
+	"absensi/models"
 	"firebase.google.com/go/auth"
+	"github.com/gorilla/mux"
 )
 
 func SetupRoutes(client *auth.Client) *mux.Router {
 	r := mux.NewRouter()
 
+	r.HandleFunc("/health", controller.HealthCheck).Methods("GET")
+
 	// Routes untuk login dan register
 	r.HandleFunc("/register", controller.Register).Methods("POST")
 	r.HandleFunc("/login", controller.Login).Methods("POST")
-	r.HandleFunc("/getUsers", controller.GetUsers).Methods("GET")
-	r.HandleFunc("/updateUserRole/{id}", controller.UpdateUserRole).Methods("PUT")
-	r.HandleFunc("/delete/{id}", controller.DeleteUser).Methods("DELETE")
+	// Aksi admin wajib login agar pelakunya tercatat di audit log
+	adminOnly := func(h http.HandlerFunc) http.Handler {
+		return middleware.AuthMiddleware(middleware.RequireRole(models.RoleAdmin)(h))
+	}
+	r.Handle("/getUsers", adminOnly(controller.GetUsers)).Methods("GET")
+	r.Handle("/updateUserRole/{id}", adminOnly(controller.UpdateUserRole)).Methods("PUT")
+	r.Handle("/delete/{id}", adminOnly(controller.DeleteUser)).Methods("DELETE")
+	r.HandleFunc("/set-password", controller.SetPassword).Methods("POST")
+
+	// Live board SSE juga menerima ?access_token= karena EventSource tidak bisa mengirim header,
+	// didaftarkan sebelum subrouter protected agar tidak tertangkap AuthMiddleware
+	r.Handle("/api/protected/attendance/live", middleware.StreamAuthMiddleware(http.HandlerFunc(controller.StreamAttendanceEvents))).Methods("GET")
 
 	// Subrouter untuk endpoint yang memerlukan autentikasi JWT
 	protected := r.PathPrefix("/api/protected").Subrouter()
@@ -24,10 +38,87 @@ func SetupRoutes(client *auth.Client) *mux.Router {
 	// Routes untuk check-in dan check-out yang hanya bisa diakses jika autentikasi berhasil
 	protected.HandleFunc("/check-in", controller.CheckIn).Methods("POST")
 	protected.HandleFunc("/check-out", controller.CheckOut).Methods("POST")
+	protected.HandleFunc("/break/start", controller.StartBreak).Methods("POST")
+	protected.HandleFunc("/break/end", controller.EndBreak).Methods("POST")
 	protected.HandleFunc("/attendance/monthly", controller.GetMonthlyAttendance).Methods("POST")
 	protected.HandleFunc("/attendance/All-User", controller.GetAllUsersMonthlyAttendance).Methods("GET")
 	protected.HandleFunc("/attendance/logs", controller.GetAttendanceLogs).Methods("GET")
+	protected.HandleFunc("/attendance/report/pdf", controller.GetMonthlyReportPDF).Methods("GET")
+
+	// Routes untuk profil user yang login
+	protected.HandleFunc("/me", controller.GetMe).Methods("GET")
+	protected.HandleFunc("/me", controller.UpdateMe).Methods("PATCH")
+	protected.HandleFunc("/me/password", controller.ChangePassword).Methods("POST")
+	protected.HandleFunc("/me/notification-preferences", controller.GetNotificationPreferences).Methods("GET")
+	protected.HandleFunc("/me/notification-preferences", controller.UpdateNotificationPreferences).Methods("PUT")
+	protected.HandleFunc("/me/devices", controller.GetMyDevices).Methods("GET")
+	protected.HandleFunc("/me/devices", controller.RegisterDevice).Methods("POST")
+	protected.HandleFunc("/me/devices/{id}", controller.DeleteDevice).Methods("DELETE")
+	protected.HandleFunc("/me/data-export", controller.ExportMyData).Methods("GET")
+	protected.HandleFunc("/me/erasure-request", controller.CreateErasureRequest).Methods("POST")
+	protected.HandleFunc("/me/digest", controller.GetMyDigest).Methods("GET")
+
+	// Routes untuk kunjungan dinas luar
+	protected.HandleFunc("/visits", controller.LogVisit).Methods("POST")
+	protected.HandleFunc("/visits/route", controller.GetVisitRoute).Methods("GET")
+
+	// Routes untuk manager melihat bawahannya
+	protected.HandleFunc("/team/reports", controller.GetTeamReports).Methods("GET")
+	protected.HandleFunc("/team/dashboard", controller.GetTeamDashboard).Methods("GET")
+	protected.HandleFunc("/team/leave", controller.GetTeamLeaveRequests).Methods("GET")
+	protected.HandleFunc("/team/leave/{id}/decision", controller.DecideLeaveRequest).Methods("PUT")
+
+	// Routes untuk pengajuan cuti
+	protected.HandleFunc("/leave", controller.CreateLeaveRequest).Methods("POST")
+	protected.HandleFunc("/leave", controller.GetMyLeaveRequests).Methods("GET")
+
+	// Subrouter khusus admin
+	admin := protected.PathPrefix("/admin").Subrouter()
+	admin.Use(middleware.RequireRole(models.RoleAdmin))
 
+	admin.HandleFunc("/departments", controller.GetDepartments).Methods("GET")
+	admin.HandleFunc("/departments", controller.CreateDepartment).Methods("POST")
+	admin.HandleFunc("/departments/{id}", controller.UpdateDepartment).Methods("PUT")
+	admin.HandleFunc("/departments/{id}", controller.DeleteDepartment).Methods("DELETE")
+	admin.HandleFunc("/teams", controller.GetTeams).Methods("GET")
+	admin.HandleFunc("/teams", controller.CreateTeam).Methods("POST")
+	admin.HandleFunc("/teams/{id}", controller.UpdateTeam).Methods("PUT")
+	admin.HandleFunc("/teams/{id}", controller.DeleteTeam).Methods("DELETE")
+	admin.HandleFunc("/users/import", controller.ImportUsers).Methods("POST")
+	admin.HandleFunc("/users/export", controller.ExportUsers).Methods("GET")
+	admin.HandleFunc("/users/{id}/org", controller.UpdateUserOrg).Methods("PUT")
+	admin.HandleFunc("/users/{id}/deactivate", controller.DeactivateUser).Methods("POST")
+	admin.HandleFunc("/users/{id}/activate", controller.ActivateUser).Methods("POST")
+	admin.HandleFunc("/users/{id}/restore", controller.RestoreUser).Methods("POST")
+	admin.HandleFunc("/users/{id}/purge", controller.PurgeUser).Methods("DELETE")
+	admin.HandleFunc("/attendance/by-department", controller.GetAttendanceByDepartment).Methods("GET")
+	admin.HandleFunc("/payroll", controller.GetPayrollExport).Methods("GET")
+	admin.HandleFunc("/fingerprint/mappings", controller.GetFingerprintMappings).Methods("GET")
+	admin.HandleFunc("/fingerprint/mappings", controller.SaveFingerprintMapping).Methods("POST")
+	admin.HandleFunc("/fingerprint/mappings/{device_user_id}", controller.DeleteFingerprintMapping).Methods("DELETE")
+	admin.HandleFunc("/fingerprint/import", controller.ImportFingerprintPunches).Methods("POST")
+	admin.HandleFunc("/audit-logs", controller.GetAuditLogs).Methods("GET")
+	admin.HandleFunc("/users/{id}/data-export", controller.ExportUserData).Methods("GET")
+	admin.HandleFunc("/erasure-requests", controller.GetErasureRequests).Methods("GET")
+	admin.HandleFunc("/erasure-requests/{id}/decision", controller.DecideErasureRequest).Methods("POST")
+	admin.HandleFunc("/retention", controller.GetRetentionPolicy).Methods("GET")
+	admin.HandleFunc("/retention/run", controller.RunRetentionNow).Methods("POST")
+	admin.HandleFunc("/retention/runs", controller.GetRetentionRuns).Methods("GET")
+	admin.HandleFunc("/outbox", controller.GetOutboxMessages).Methods("GET")
+	admin.HandleFunc("/outbox/retry-dead", controller.RetryDeadOutboxMessages).Methods("POST")
+	admin.HandleFunc("/outbox/{id}/retry", controller.RetryOutboxMessage).Methods("POST")
+	admin.HandleFunc("/notification-templates", controller.GetNotificationTemplates).Methods("GET")
+	admin.HandleFunc("/notification-templates/{key}/{lang}", controller.UpdateNotificationTemplate).Methods("PUT")
+	admin.HandleFunc("/notification-templates/{key}/{lang}", controller.ResetNotificationTemplate).Methods("DELETE")
+	admin.HandleFunc("/notification-templates/{key}/{lang}/preview", controller.PreviewNotificationTemplate).Methods("POST")
+	admin.HandleFunc("/webhooks", controller.GetWebhooks).Methods("GET")
+	admin.HandleFunc("/webhooks", controller.CreateWebhook).Methods("POST")
+	admin.HandleFunc("/webhooks/deliveries/{id}/retry", controller.RetryWebhookDelivery).Methods("POST")
+	admin.HandleFunc("/webhooks/{id}", controller.UpdateWebhook).Methods("PUT")
+	admin.HandleFunc("/webhooks/{id}", controller.DeleteWebhook).Methods("DELETE")
+	admin.HandleFunc("/webhooks/{id}/rotate-secret", controller.RotateWebhookSecret).Methods("POST")
+	admin.HandleFunc("/webhooks/{id}/test", controller.TestWebhook).Methods("POST")
+	admin.HandleFunc("/webhooks/{id}/deliveries", controller.GetWebhookDeliveries).Methods("GET")
 
 	return r
 }
@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"os"
+	"strings"
+	"time"
+)
+
+// DigestSchedule adalah jadwal pengiriman ringkasan kehadiran untuk manager
+type DigestSchedule struct {
+	Hour, Minute int
+	Weekday      time.Weekday // Hari pengiriman ringkasan mingguan
+}
+
+// GetDigestSchedule membaca jam kirim dari env DIGEST_TIME (format HH:MM, waktu lokal server)
+// dan hari ringkasan mingguan dari DIGEST_WEEKDAY (nama hari dalam bahasa Inggris), default Senin 07:00
+func GetDigestSchedule() DigestSchedule {
+	s := DigestSchedule{Hour: 7, Weekday: time.Monday}
+
+	if t, err := time.Parse("15:04", os.Getenv("DIGEST_TIME")); err == nil {
+		s.Hour, s.Minute = t.Hour(), t.Minute()
+	}
+	if day := strings.ToLower(strings.TrimSpace(os.Getenv("DIGEST_WEEKDAY"))); day != "" {
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			if strings.ToLower(d.String()) == day {
+				s.Weekday = d
+			}
+		}
+	}
+	return s
+}
+
+// SendOn mengembalikan waktu kirim ringkasan pada tanggal day
+func (s DigestSchedule) SendOn(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), s.Hour, s.Minute, 0, 0, day.Location())
+}
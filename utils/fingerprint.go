@@ -0,0 +1,147 @@
+package utils
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+// Arah punch dari mesin fingerprint
+const (
+	PunchIn   = "in"
+	PunchOut  = "out"
+	PunchAuto = "auto" // Mesin tidak mengirim status, ditentukan dari urutan punch
+	PunchSkip = "skip" // Status yang tidak dipakai (istirahat)
+)
+
+// Punch adalah satu baris data dari mesin fingerprint
+type Punch struct {
+	Line         int
+	DeviceUserID string
+	Time         time.Time
+	Direction    string
+	Err          error // Baris tidak valid, field lain mungkin kosong
+}
+
+var punchTimeLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006/01/02 15:04:05",
+	"02/01/2006 15:04:05",
+	"2006-01-02T15:04:05",
+}
+
+func parsePunchTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range punchTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("invalid timestamp " + s)
+}
+
+// punchDirection menerjemahkan kode status ZKTeco:
+// 0 check-in, 1 check-out, 2 break-out, 3 break-in, 4 lembur masuk, 5 lembur keluar
+func punchDirection(state string) string {
+	switch strings.TrimSpace(strings.ToLower(state)) {
+	case "":
+		return PunchAuto
+	case "0", "4", "in", "checkin", "check_in", "c/in":
+		return PunchIn
+	case "1", "5", "out", "checkout", "check_out", "c/out":
+		return PunchOut
+	default:
+		return PunchSkip
+	}
+}
+
+// ParseAttlog membaca file attlog.dat ZKTeco: kolom dipisah tab
+// (user id, "YYYY-MM-DD HH:MM:SS", verify mode, status, workcode, ...)
+func ParseAttlog(r io.Reader) ([]Punch, error) {
+	var punches []Punch
+	scanner := bufio.NewScanner(SkipBOM(r))
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		fields := strings.Split(text, "\t")
+		punch := Punch{Line: line}
+		if len(fields) < 2 {
+			punch.Err = errors.New("expected at least user id and timestamp")
+			punches = append(punches, punch)
+			continue
+		}
+
+		punch.DeviceUserID = strings.TrimSpace(fields[0])
+		punch.Time, punch.Err = parsePunchTime(fields[1])
+		punch.Direction = PunchAuto
+		if len(fields) >= 4 {
+			punch.Direction = punchDirection(fields[3])
+		}
+		punches = append(punches, punch)
+	}
+	return punches, scanner.Err()
+}
+
+// ParsePunchCSV membaca file CSV dengan kolom user_id, timestamp dan (opsional) status.
+// Baris header dilewati jika kolom timestamp bukan waktu yang valid di baris pertama yang berisi data.
+func ParsePunchCSV(r io.Reader) ([]Punch, error) {
+	reader := csv.NewReader(SkipBOM(r))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var punches []Punch
+	first := true
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// Nomor baris diambil dari error agar sesuai dengan baris di file
+			line := 0
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				line = parseErr.Line
+			}
+			punches = append(punches, Punch{Line: line, Err: err})
+			first = false
+			continue
+		}
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+		// Baris kosong dilewati csv.Reader, jadi nomor baris diambil dari posisi field pertama
+		line, _ := reader.FieldPos(0)
+
+		punch := Punch{Line: line}
+		if len(record) < 2 {
+			punch.Err = errors.New("expected at least user id and timestamp")
+			punches = append(punches, punch)
+			first = false
+			continue
+		}
+
+		punch.DeviceUserID = strings.TrimSpace(record[0])
+		punch.Time, punch.Err = parsePunchTime(record[1])
+		header := first && punch.Err != nil
+		first = false
+		if header {
+			continue
+		}
+		punch.Direction = PunchAuto
+		if len(record) >= 3 {
+			punch.Direction = punchDirection(record[2])
+		}
+		punches = append(punches, punch)
+	}
+	return punches, nil
+}
@@ -0,0 +1,154 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// wantPunch adalah hasil yang diharapkan untuk satu baris; time kosong berarti baris tidak valid
+type wantPunch struct {
+	line      int
+	userID    string
+	time      string
+	direction string
+}
+
+func checkPunches(t *testing.T, got []Punch, want []wantPunch) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d punches, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		p := got[i]
+		if p.Line != w.line {
+			t.Errorf("punch %d: line = %d, want %d", i, p.Line, w.line)
+		}
+		if w.time == "" {
+			if p.Err == nil {
+				t.Errorf("punch %d: expected an error, got %+v", i, p)
+			}
+			continue
+		}
+		if p.Err != nil {
+			t.Errorf("punch %d: unexpected error %v", i, p.Err)
+			continue
+		}
+		wantTime, _ := time.ParseInLocation("2006-01-02 15:04:05", w.time, time.Local)
+		if p.DeviceUserID != w.userID || !p.Time.Equal(wantTime) || p.Direction != w.direction {
+			t.Errorf("punch %d = {%q %s %s}, want {%q %s %s}", i, p.DeviceUserID, p.Time, p.Direction, w.userID, wantTime, w.direction)
+		}
+	}
+}
+
+func TestParseAttlog(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []wantPunch
+	}{
+		{
+			name:  "status codes",
+			input: "101\t2026-10-19 08:01:02\t1\t0\t0\n101\t2026-10-19 17:05:00\t1\t1\t0\n102\t2026-10-19 12:00:00\t1\t2\t0\n",
+			want: []wantPunch{
+				{1, "101", "2026-10-19 08:01:02", PunchIn},
+				{2, "101", "2026-10-19 17:05:00", PunchOut},
+				{3, "102", "2026-10-19 12:00:00", PunchSkip},
+			},
+		},
+		{
+			name:  "byte order mark",
+			input: "\ufeff101\t2026-10-19 08:01:02\t1\t0\n",
+			want:  []wantPunch{{1, "101", "2026-10-19 08:01:02", PunchIn}},
+		},
+		{
+			name:  "blank lines and windows line endings",
+			input: "\r\n101\t2026-10-19 08:01:02\r\n\r\n   \r\n102\t2026-10-19 08:05:00\t1\t0\r\n",
+			want: []wantPunch{
+				{2, "101", "2026-10-19 08:01:02", PunchAuto},
+				{5, "102", "2026-10-19 08:05:00", PunchIn},
+			},
+		},
+		{
+			name:  "bad timestamp and missing columns",
+			input: "101\t19-10-2026 8am\t1\t0\n102\n103\t2026-10-19T08:00:00\n",
+			want: []wantPunch{
+				{line: 1},
+				{line: 2},
+				{3, "103", "2026-10-19 08:00:00", PunchAuto},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAttlog(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("ParseAttlog() error = %v", err)
+			}
+			checkPunches(t, got, tt.want)
+		})
+	}
+}
+
+func TestParsePunchCSV(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []wantPunch
+	}{
+		{
+			name:  "header and status",
+			input: "user_id,timestamp,status\n101,2026-10-19 08:01:02,in\n101,2026-10-19 17:05,out\n",
+			want: []wantPunch{
+				{2, "101", "2026-10-19 08:01:02", PunchIn},
+				{3, "101", "2026-10-19 17:05:00", PunchOut},
+			},
+		},
+		{
+			name:  "byte order mark before header",
+			input: "\ufeffuser_id,timestamp\n101,2026-10-19 08:01:02\n",
+			want:  []wantPunch{{2, "101", "2026-10-19 08:01:02", PunchAuto}},
+		},
+		{
+			name:  "byte order mark without header",
+			input: "\ufeff101,2026/10/19 08:01:02,0\n",
+			want:  []wantPunch{{1, "101", "2026-10-19 08:01:02", PunchIn}},
+		},
+		{
+			name:  "blank lines keep file line numbers",
+			input: "\nuser_id,timestamp\n\n101,19/10/2026 08:01:02\n\n102,2026-10-19 08:05:00\n",
+			want: []wantPunch{
+				{4, "101", "2026-10-19 08:01:02", PunchAuto},
+				{6, "102", "2026-10-19 08:05:00", PunchAuto},
+			},
+		},
+		{
+			name:  "bad timestamp after first row",
+			input: "101,2026-10-19 08:01:02\n102,not a time\n103\n",
+			want: []wantPunch{
+				{1, "101", "2026-10-19 08:01:02", PunchAuto},
+				{line: 2},
+				{line: 3},
+			},
+		},
+		{
+			name:  "malformed quote",
+			input: "101,2026-10-19 08:01:02\n\"102,2026-10-19 08:05:00\n",
+			want: []wantPunch{
+				{1, "101", "2026-10-19 08:01:02", PunchAuto},
+				{line: 2},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePunchCSV(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("ParsePunchCSV() error = %v", err)
+			}
+			checkPunches(t, got, tt.want)
+		})
+	}
+}
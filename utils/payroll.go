@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+)
+
+// OvertimeTier adalah pengali lembur sampai jam ke-UpToHours (kumulatif).
+// UpToHours 0 berarti berlaku untuk semua jam sisanya.
+type OvertimeTier struct {
+	UpToHours  float64 `json:"up_to_hours"`
+	Multiplier float64 `json:"multiplier"`
+}
+
+// PayrollRules adalah aturan perhitungan payroll dari data kehadiran
+type PayrollRules struct {
+	// Pengali lembur hari kerja dan hari istirahat
+	WeekdayOvertime []OvertimeTier `json:"weekday_overtime"`
+	RestDayOvertime []OvertimeTier `json:"rest_day_overtime"`
+
+	// Keterlambatan di bawah toleransi per hari tidak dipotong,
+	// sisanya dibulatkan ke atas per blok LateRoundingMinutes
+	LateToleranceMinutes int `json:"late_tolerance_minutes"`
+	LateRoundingMinutes  int `json:"late_rounding_minutes"`
+
+	// Jenis cuti yang tetap dibayar, jenis lain dihitung cuti tidak dibayar
+	PaidLeaveTypes []string `json:"paid_leave_types"`
+}
+
+// DefaultPayrollRules mengikuti tarif lembur PP 35/2021 untuk 5 hari kerja:
+// hari kerja jam pertama 1,5x dan selanjutnya 2x; hari istirahat jam 1-8 2x, jam ke-9 3x, jam ke-10 dst 4x
+func DefaultPayrollRules() PayrollRules {
+	return PayrollRules{
+		WeekdayOvertime: []OvertimeTier{{UpToHours: 1, Multiplier: 1.5}, {Multiplier: 2}},
+		RestDayOvertime: []OvertimeTier{{UpToHours: 8, Multiplier: 2}, {UpToHours: 9, Multiplier: 3}, {Multiplier: 4}},
+		PaidLeaveTypes:  []string{"annual", "sick", "maternity", "marriage", "bereavement"},
+	}
+}
+
+// LoadPayrollRules membaca aturan dari file JSON di env PAYROLL_RULES_FILE.
+// Field yang tidak diisi memakai nilai default.
+func LoadPayrollRules() (PayrollRules, error) {
+	rules := DefaultPayrollRules()
+
+	path := os.Getenv("PAYROLL_RULES_FILE")
+	if path == "" {
+		return rules, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return rules, err
+	}
+	err = json.Unmarshal(data, &rules)
+	return rules, err
+}
+
+// WeightedOvertime menghitung jam lembur tertimbang (jam x pengali) berdasarkan tier
+func WeightedOvertime(hours float64, tiers []OvertimeTier) float64 {
+	total, counted := 0.0, 0.0
+	for _, tier := range tiers {
+		if hours <= counted {
+			break
+		}
+		upper := hours
+		if tier.UpToHours > 0 && tier.UpToHours < hours {
+			upper = tier.UpToHours
+		}
+		if upper > counted {
+			total += (upper - counted) * tier.Multiplier
+			counted = upper
+		}
+	}
+	return math.Round(total*100) / 100
+}
+
+// DeductibleLateMinutes menerapkan toleransi dan pembulatan pada keterlambatan satu hari
+func (p PayrollRules) DeductibleLateMinutes(lateMinutes int) int {
+	if lateMinutes <= p.LateToleranceMinutes {
+		return 0
+	}
+	if p.LateRoundingMinutes > 1 {
+		blocks := (lateMinutes + p.LateRoundingMinutes - 1) / p.LateRoundingMinutes
+		return blocks * p.LateRoundingMinutes
+	}
+	return lateMinutes
+}
+
+// IsPaidLeave mengecek apakah jenis cuti termasuk cuti dibayar
+func (p PayrollRules) IsPaidLeave(leaveType string) bool {
+	for _, t := range p.PaidLeaveTypes {
+		if t == leaveType {
+			return true
+		}
+	}
+	return false
+}
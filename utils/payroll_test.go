@@ -0,0 +1,63 @@
+package utils
+
+import "testing"
+
+func TestWeightedOvertime(t *testing.T) {
+	rules := DefaultPayrollRules()
+	tests := []struct {
+		name  string
+		hours float64
+		tiers []OvertimeTier
+		want  float64
+	}{
+		{"no overtime", 0, rules.WeekdayOvertime, 0},
+		{"weekday within first hour", 0.5, rules.WeekdayOvertime, 0.75},
+		{"weekday exactly first hour", 1, rules.WeekdayOvertime, 1.5},
+		{"weekday past first hour", 3, rules.WeekdayOvertime, 1.5 + 2*2},
+		{"rest day within first tier", 5, rules.RestDayOvertime, 10},
+		{"rest day exactly eight hours", 8, rules.RestDayOvertime, 16},
+		{"rest day ninth hour", 8.5, rules.RestDayOvertime, 16 + 0.5*3},
+		{"rest day exactly nine hours", 9, rules.RestDayOvertime, 16 + 3},
+		{"rest day past ninth hour", 11, rules.RestDayOvertime, 16 + 3 + 2*4},
+		{"last tier capped", 10, []OvertimeTier{{UpToHours: 2, Multiplier: 1.5}, {UpToHours: 4, Multiplier: 2}}, 3 + 4},
+		{"no tiers", 4, nil, 0},
+		{"rounded to two decimals", 1.0 / 3, rules.WeekdayOvertime, 0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WeightedOvertime(tt.hours, tt.tiers); got != tt.want {
+				t.Errorf("WeightedOvertime(%v) = %v, want %v", tt.hours, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeductibleLateMinutes(t *testing.T) {
+	tests := []struct {
+		name      string
+		tolerance int
+		rounding  int
+		late      int
+		want      int
+	}{
+		{"on time", 0, 0, 0, 0},
+		{"no rules", 0, 0, 7, 7},
+		{"within tolerance", 10, 0, 10, 0},
+		{"past tolerance", 10, 0, 11, 11},
+		{"rounded up to block", 0, 15, 1, 15},
+		{"exact block", 0, 15, 30, 30},
+		{"just past block", 0, 15, 31, 45},
+		{"tolerance then rounding", 5, 15, 6, 15},
+		{"rounding of one minute", 0, 1, 7, 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := PayrollRules{LateToleranceMinutes: tt.tolerance, LateRoundingMinutes: tt.rounding}
+			if got := rules.DeductibleLateMinutes(tt.late); got != tt.want {
+				t.Errorf("DeductibleLateMinutes(%d) = %d, want %d", tt.late, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"log"
+	"net"
+	"os"
+	"strings"
+)
+
+// TrustedProxies adalah daftar reverse proxy yang boleh mengisi header X-Forwarded-For
+type TrustedProxies []*net.IPNet
+
+// GetTrustedProxies membaca env TRUSTED_PROXIES: daftar IP atau CIDR dipisah koma,
+// misalnya "10.0.0.0/8,127.0.0.1". Kosong berarti X-Forwarded-For tidak pernah dipercaya.
+func GetTrustedProxies() TrustedProxies {
+	var proxies TrustedProxies
+	for _, entry := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			log.Println("Ignoring invalid TRUSTED_PROXIES entry:", entry)
+			continue
+		}
+		proxies = append(proxies, network)
+	}
+	return proxies
+}
+
+// Contains mengecek apakah ip termasuk proxy terpercaya
+func (t TrustedProxies) Contains(ip string) bool {
+	parsed := net.ParseIP(strings.TrimSpace(ip))
+	if parsed == nil {
+		return false
+	}
+	for _, network := range t {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// RetentionPolicy adalah aturan penyimpanan koordinat GPS kehadiran
+type RetentionPolicy struct {
+	// Koordinat lebih tua dari CoarsenAfterDays dibulatkan ke CoarsenDecimals angka desimal
+	// (2 desimal kira-kira 1,1 km). 0 berarti tidak pernah dibulatkan.
+	CoarsenAfterDays int `json:"coarsen_after_days"`
+	CoarsenDecimals  int `json:"coarsen_decimals"`
+
+	// Koordinat lebih tua dari DeleteAfterDays dihapus (NULL). 0 berarti tidak pernah dihapus.
+	DeleteAfterDays int `json:"delete_after_days"`
+
+	// Masa retensi wajib sebelum permintaan penghapusan data yang disetujui dijalankan.
+	// 0 berarti data langsung dianonimkan saat permintaan disetujui.
+	ErasureRetentionDays int `json:"erasure_retention_days"`
+
+	// Interval job retensi berjalan di background
+	Interval time.Duration `json:"-"`
+}
+
+// GetRetentionPolicy membaca kebijakan retensi dari env LOCATION_COARSEN_DAYS, LOCATION_COARSEN_DECIMALS,
+// LOCATION_DELETE_DAYS, ERASURE_RETENTION_DAYS dan RETENTION_INTERVAL (durasi Go, default 24h).
+// Tanpa konfigurasi, tidak ada data yang diubah.
+func GetRetentionPolicy() RetentionPolicy {
+	p := RetentionPolicy{CoarsenDecimals: 2, Interval: 24 * time.Hour}
+
+	if d, err := strconv.Atoi(os.Getenv("LOCATION_COARSEN_DAYS")); err == nil && d > 0 {
+		p.CoarsenAfterDays = d
+	}
+	if d, err := strconv.Atoi(os.Getenv("LOCATION_COARSEN_DECIMALS")); err == nil && d >= 0 && d <= 5 {
+		p.CoarsenDecimals = d
+	}
+	if d, err := strconv.Atoi(os.Getenv("LOCATION_DELETE_DAYS")); err == nil && d > 0 {
+		p.DeleteAfterDays = d
+	}
+	if d, err := strconv.Atoi(os.Getenv("ERASURE_RETENTION_DAYS")); err == nil && d > 0 {
+		p.ErasureRetentionDays = d
+	}
+	if d, err := time.ParseDuration(os.Getenv("RETENTION_INTERVAL")); err == nil && d >= time.Minute {
+		p.Interval = d
+	}
+	return p
+}
+
+// Enabled bernilai true jika ada aturan retensi lokasi yang aktif
+func (p RetentionPolicy) Enabled() bool {
+	return p.CoarsenAfterDays > 0 || p.DeleteAfterDays > 0
+}
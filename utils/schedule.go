@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// Schedule adalah jam kerja standar perusahaan
+type Schedule struct {
+	StartHour, StartMinute int
+	EndHour, EndMinute     int
+	Grace                  time.Duration // Toleransi keterlambatan
+}
+
+// GetSchedule membaca jam kerja dari env WORK_START, WORK_END (format HH:MM)
+// dan LATE_GRACE_MINUTES, default 08:00-17:00 tanpa toleransi
+func GetSchedule() Schedule {
+	s := Schedule{StartHour: 8, EndHour: 17}
+
+	if t, err := time.Parse("15:04", os.Getenv("WORK_START")); err == nil {
+		s.StartHour, s.StartMinute = t.Hour(), t.Minute()
+	}
+	if t, err := time.Parse("15:04", os.Getenv("WORK_END")); err == nil {
+		s.EndHour, s.EndMinute = t.Hour(), t.Minute()
+	}
+	if m, err := strconv.Atoi(os.Getenv("LATE_GRACE_MINUTES")); err == nil && m > 0 {
+		s.Grace = time.Duration(m) * time.Minute
+	}
+	return s
+}
+
+// StartOn mengembalikan waktu mulai kerja pada tanggal day
+func (s Schedule) StartOn(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), s.StartHour, s.StartMinute, 0, 0, day.Location())
+}
+
+// EndOn mengembalikan waktu selesai kerja pada tanggal day
+func (s Schedule) EndOn(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), s.EndHour, s.EndMinute, 0, 0, day.Location())
+}
+
+// LateMinutes menghitung keterlambatan check-in dalam menit, 0 jika tepat waktu
+func (s Schedule) LateMinutes(checkIn time.Time) int {
+	limit := s.StartOn(checkIn).Add(s.Grace)
+	if !checkIn.After(limit) {
+		return 0
+	}
+	return int(checkIn.Sub(s.StartOn(checkIn)).Minutes())
+}
+
+// IsWorkday bernilai true untuk Senin sampai Jumat
+func (s Schedule) IsWorkday(day time.Time) bool {
+	return day.Weekday() != time.Saturday && day.Weekday() != time.Sunday
+}
+
+// PreviousWorkday mengembalikan hari kerja terakhir sebelum tanggal day (Jumat jika day adalah Senin)
+func (s Schedule) PreviousWorkday(day time.Time) time.Time {
+	prev := time.Date(day.Year(), day.Month(), day.Day()-1, 0, 0, 0, 0, day.Location())
+	for i := 0; i < 7 && !s.IsWorkday(prev); i++ {
+		prev = prev.AddDate(0, 0, -1)
+	}
+	return prev
+}
@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPreviousWorkday(t *testing.T) {
+	loc := time.FixedZone("WIB", 7*3600)
+	tests := []struct {
+		name string
+		day  time.Time
+		want time.Time
+	}{
+		{"monday goes back to friday", time.Date(2026, 10, 19, 9, 0, 0, 0, loc), time.Date(2026, 10, 16, 0, 0, 0, 0, loc)},
+		{"tuesday goes back to monday", time.Date(2026, 10, 20, 9, 0, 0, 0, loc), time.Date(2026, 10, 19, 0, 0, 0, 0, loc)},
+		{"sunday goes back to friday", time.Date(2026, 10, 18, 9, 0, 0, 0, loc), time.Date(2026, 10, 16, 0, 0, 0, 0, loc)},
+		{"across month boundary", time.Date(2026, 6, 1, 0, 0, 0, 0, loc), time.Date(2026, 5, 29, 0, 0, 0, 0, loc)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (Schedule{}).PreviousWorkday(tt.day); !got.Equal(tt.want) {
+				t.Errorf("PreviousWorkday(%s) = %s, want %s", tt.day, got, tt.want)
+			}
+		})
+	}
+}
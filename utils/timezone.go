@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+)
+
+// PGTimeZone mengembalikan zona waktu t dalam format yang diterima AT TIME ZONE di Postgres, sehingga
+// pengelompokan per tanggal di database mengikuti zona waktu server, bukan zona waktu sesi database.
+// Jika nama IANA tidak diketahui (time.Local dibaca dari /etc/localtime), dipakai spesifikasi POSIX
+// berdasarkan offset saat t, misalnya "<+07>-07" untuk WIB.
+func PGTimeZone(t time.Time) string {
+	if name := t.Location().String(); name != "" && name != "Local" {
+		return name
+	}
+
+	_, offset := t.Zone()
+	sign := "+"
+	if offset < 0 {
+		sign, offset = "-", -offset
+	}
+	hours, minutes := offset/3600, offset%3600/60
+	abbr := fmt.Sprintf("%s%02d", sign, hours)
+	if minutes != 0 {
+		abbr += fmt.Sprintf("%02d", minutes)
+	}
+
+	// Offset POSIX bertanda terbalik: zona di timur Greenwich ditulis negatif
+	posixSign := "-"
+	if sign == "-" {
+		posixSign = "+"
+	}
+	return fmt.Sprintf("<%s>%s%02d:%02d", abbr, posixSign, hours, minutes)
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"os"
+	"strconv"
+)
+
+// reservedNetworks adalah rentang alamat yang bukan internet publik selain yang sudah dikenali
+// method net.IP (loopback, private, link-local, multicast, unspecified)
+var reservedNetworks = func() []*net.IPNet {
+	networks := []*net.IPNet{}
+	for _, cidr := range []string{
+		"0.0.0.0/8",      // "Jaringan ini"
+		"100.64.0.0/10",  // Carrier-grade NAT
+		"192.0.0.0/24",   // IETF protocol assignments
+		"198.18.0.0/15",  // Benchmarking
+		"240.0.0.0/4",    // Reserved dan broadcast
+		"64:ff9b::/96",   // NAT64, bisa menunjuk ke alamat IPv4 internal
+		"64:ff9b:1::/48", // NAT64 lokal
+		"2001:db8::/32",  // Dokumentasi
+	} {
+		_, network, _ := net.ParseCIDR(cidr)
+		networks = append(networks, network)
+	}
+	return networks
+}()
+
+// SignWebhook menghitung tanda tangan HMAC-SHA256 dari "timestamp.body" dengan secret endpoint.
+// Penerima menghitung ulang nilai yang sama dan membandingkannya dengan header X-Absensi-Signature.
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// IsPublicIP mengecek apakah ip adalah alamat internet publik. Loopback, jaringan privat (RFC 1918, fc00::/7),
+// link-local (termasuk metadata cloud 169.254.169.254), multicast dan rentang reserved dianggap bukan publik.
+func IsPublicIP(ip net.IP) bool {
+	if ip == nil || ip.IsUnspecified() || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, network := range reservedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// WebhookTargetAllowed mengecek apakah webhook boleh dikirim ke ip. Hanya alamat publik yang diizinkan
+// kecuali env WEBHOOK_ALLOW_PRIVATE=true, yang dipakai untuk menguji penerima webhook di jaringan lokal.
+func WebhookTargetAllowed(ip net.IP) bool {
+	return IsPublicIP(ip) || os.Getenv("WEBHOOK_ALLOW_PRIVATE") == "true"
+}
@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"net"
+	"testing"
+)
+
+func TestSignWebhook(t *testing.T) {
+	// Nilai yang diharapkan dihitung dengan openssl, misalnya:
+	// printf '1760850000.{"id":"evt_1"}' | openssl dgst -sha256 -hmac whsec_test
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp int64
+		body      string
+		want      string
+	}{
+		{"signs timestamp and body", "whsec_test", 1760850000, `{"id":"evt_1"}`,
+			"sha256=d6353abb5ff6bd3cc7c72cd8c79c81e46add36a71d504c6dbe40502d2d9f3241"},
+		{"empty body", "whsec_test", 1760850000, "",
+			"sha256=bd429296d552dcef8e51cc16c4008695ea36595881eb58ef0a0f615685eba371"},
+		{"empty secret", "", 0, `{}`,
+			"sha256=4fa6c2486692767ff3eb0ad23d9638df613add15a49b8ffc0a606879b90a6f25"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SignWebhook(tt.secret, tt.timestamp, []byte(tt.body)); got != tt.want {
+				t.Errorf("SignWebhook() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	base := SignWebhook("whsec_test", 1760850000, []byte(`{"id":"evt_1"}`))
+	if SignWebhook("whsec_other", 1760850000, []byte(`{"id":"evt_1"}`)) == base {
+		t.Error("signature does not depend on the secret")
+	}
+	if SignWebhook("whsec_test", 1760850001, []byte(`{"id":"evt_1"}`)) == base {
+		t.Error("signature does not depend on the timestamp")
+	}
+	if SignWebhook("whsec_test", 1760850000, []byte(`{"id":"evt_2"}`)) == base {
+		t.Error("signature does not depend on the body")
+	}
+}
+
+func TestIsPublicIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"8.8.8.8", true},
+		{"203.0.114.10", true},
+		{"2606:4700:4700::1111", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00:ec2::254", false},
+		{"100.64.0.1", false},
+		{"224.0.0.1", false},
+		{"255.255.255.255", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:10.0.0.1", false},
+		{"64:ff9b::a00:1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			if got := IsPublicIP(net.ParseIP(tt.ip)); got != tt.want {
+				t.Errorf("IsPublicIP(%s) = %v, want %v", tt.ip, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebhookTargetAllowed(t *testing.T) {
+	loopback := net.ParseIP("127.0.0.1")
+
+	t.Setenv("WEBHOOK_ALLOW_PRIVATE", "")
+	if WebhookTargetAllowed(loopback) {
+		t.Error("loopback allowed without WEBHOOK_ALLOW_PRIVATE")
+	}
+
+	t.Setenv("WEBHOOK_ALLOW_PRIVATE", "true")
+	if !WebhookTargetAllowed(loopback) {
+		t.Error("loopback rejected with WEBHOOK_ALLOW_PRIVATE=true")
+	}
+}